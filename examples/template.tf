@@ -1,7 +1,6 @@
 data "jinja_template" "render" {
   context {
-    type = "yaml"
-    data = file("${path.module}/src/context.yaml")
+    file = "context.yaml"
   }
   source {
     template  = file("${path.module}/src/template.j2")
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -38,18 +39,28 @@ type TemplateDataSource struct {
 }
 
 type TemplateDataSourceModel struct {
-	Source          types.List     `tfsdk:"source"`
-	Context         types.List     `tfsdk:"context"`
-	Validation      types.Map      `tfsdk:"validation"`
-	StrictUndefined types.Bool     `tfsdk:"strict_undefined"`
-	TrimBlocks      types.Bool     `tfsdk:"trim_blocks"`
-	LeftStripBlocks types.Bool     `tfsdk:"left_strip_blocks"`
-	Delimiters      types.Object   `tfsdk:"delimiters"`
-	Timeouts        timeouts.Value `tfsdk:"timeouts"`
+	Source              types.List     `tfsdk:"source"`
+	Context             types.List     `tfsdk:"context"`
+	Set                 types.List     `tfsdk:"set"`
+	Overrides           types.Map      `tfsdk:"overrides"`
+	Validation          types.Map      `tfsdk:"validation"`
+	MergedContextFormat types.String   `tfsdk:"merged_context_format"`
+	Redact              types.List     `tfsdk:"redact"`
+	StrictUndefined     types.Bool     `tfsdk:"strict_undefined"`
+	StrictParsing       types.Bool     `tfsdk:"strict_parsing"`
+	TrimBlocks          types.Bool     `tfsdk:"trim_blocks"`
+	LeftStripBlocks     types.Bool     `tfsdk:"left_strip_blocks"`
+	Delimiters          types.Object   `tfsdk:"delimiters"`
+	FrozenTime          types.String   `tfsdk:"frozen_time"`
+	Sensitive           types.Bool     `tfsdk:"sensitive"`
+	Timeouts            timeouts.Value `tfsdk:"timeouts"`
 	// Computed
-	Result        types.String `tfsdk:"result"`
-	MergedContext types.String `tfsdk:"merged_context"`
-	ID            types.String `tfsdk:"id"`
+	Result                 types.String `tfsdk:"result"`
+	MergedContext          types.String `tfsdk:"merged_context"`
+	SensitiveResult        types.String `tfsdk:"sensitive_result"`
+	SensitiveMergedContext types.String `tfsdk:"sensitive_merged_context"`
+	Inputs                 types.Map    `tfsdk:"inputs"`
+	ID                     types.String `tfsdk:"id"`
 	// Deprecated
 	Header   types.String `tfsdk:"header"`
 	Footer   types.String `tfsdk:"footer"`
@@ -59,9 +70,26 @@ type SourceModel struct {
 	Template  types.String `tfsdk:"template"`
 	Directory types.String `tfsdk:"directory"`
 }
+type SetModel struct {
+	Path  types.String `tfsdk:"path"`
+	Value types.String `tfsdk:"value"`
+	Type  types.String `tfsdk:"type"`
+}
 type ContextModel struct {
-	Type types.String `tfsdk:"type"`
-	Data types.String `tfsdk:"data"`
+	Type           types.String `tfsdk:"type"`
+	Data           types.String `tfsdk:"data"`
+	File           types.String `tfsdk:"file"`
+	Key            types.String `tfsdk:"key"`
+	Delimiter      types.String `tfsdk:"delimiter"`
+	Header         types.Bool   `tfsdk:"header"`
+	InferTypes     types.Bool   `tfsdk:"infer_types"`
+	IndexBy        types.String `tfsdk:"index_by"`
+	AllowSensitive types.Bool   `tfsdk:"allow_sensitive"`
+	Render         types.Bool   `tfsdk:"render"`
+	YAMLTags       types.Bool   `tfsdk:"yaml_tags"`
+	Encrypted      types.Bool   `tfsdk:"encrypted"`
+	Prefix         types.String `tfsdk:"prefix"`
+	Separator      types.String `tfsdk:"separator"`
 }
 
 func (d *TemplateDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
@@ -139,21 +167,99 @@ func (d *TemplateDataSource) Schema(ctx context.Context, req datasource.SchemaRe
 				NestedObject: schema.NestedBlockObject{
 					Attributes: map[string]schema.Attribute{
 						"type": schema.StringAttribute{
-							Required:            true,
-							MarkdownDescription: fmt.Sprintf("Type of parsing (one of: `%s`) to perform on the given string", strings.Join(lib.SupportedValuesFormats, "`,`")),
+							Optional:            true,
+							MarkdownDescription: fmt.Sprintf("Type of parsing (one of: `%s`) to perform on the given string. Required when using `data`, inferred from the extension when using `file` if not set. The `environment` type loads environment variables instead, see `prefix` and `separator`", strings.Join(lib.SupportedValuesFormats, "`,`")),
 							Validators: []validator.String{
 								stringvalidator.OneOf(lib.SupportedValuesFormats...),
 							},
 						},
 						"data": schema.StringAttribute{
+							Optional:            true,
+							MarkdownDescription: "A string holding the serialized context. Conflicts with `file`",
+							Validators: []validator.String{
+								stringvalidator.ConflictsWith(path.MatchRelative().AtParent().AtName("file")),
+							},
+						},
+						"file": schema.StringAttribute{
+							Optional:            true,
+							MarkdownDescription: "Path, relative to the source directory, of a file holding the serialized context. Glob patterns such as `values/**/*.yaml` are expanded into one context layer per matching file, merged in lexicographic order. Conflicts with `data`",
+						},
+						"key": schema.StringAttribute{
+							Optional:            true,
+							MarkdownDescription: "Dotted path, such as `services.api`, under which the decoded context is nested before being merged, which makes lists and scalars valid contexts. Dots that are part of a key can be escaped with a backslash. Required for contexts that do not decode into a dictionary, such as `csv`, `tsv` and `ndjson` ones",
+						},
+						"prefix": schema.StringAttribute{
+							Optional:            true,
+							MarkdownDescription: "Prefix of the names of the environment variables loaded by `environment` contexts, such as `APP_`. Required and non-empty for `environment` contexts. The prefix is removed and the rest of each name is lower cased and split on `separator` into nested keys",
+						},
+						"separator": schema.StringAttribute{
+							Optional:            true,
+							MarkdownDescription: "Separator splitting the names of the environment variables loaded by `environment` contexts into nested keys, so that `APP_DB__HOST` is loaded as `db.host` with the `APP_` prefix. Defaults to `__`",
+						},
+						"delimiter": schema.StringAttribute{
+							Optional:            true,
+							MarkdownDescription: "Single character separating fields of `csv` and `tsv` contexts. Defaults to `,` for `csv` and to a tabulation for `tsv`",
+						},
+						"header": schema.BoolAttribute{
+							Optional:            true,
+							MarkdownDescription: "Set to `false` when the first line of a `csv` or `tsv` context is not a header row, in which case rows are loaded as lists instead of dictionaries. Defaults to `true`",
+						},
+						"infer_types": schema.BoolAttribute{
+							Optional:            true,
+							MarkdownDescription: "Set to `true` to convert fields of `csv` and `tsv` contexts looking like booleans, integers or floats instead of loading them as strings, except for numbers padded with leading zeros such as `007`",
+						},
+						"index_by": schema.StringAttribute{
+							Optional:            true,
+							MarkdownDescription: "Name of a column of `csv` and `tsv` contexts, or of a field of `ndjson` contexts, whose unique values are used as keys to load rows as a dictionary instead of a list",
+						},
+						"render": schema.BoolAttribute{
+							Optional:            true,
+							MarkdownDescription: "Set to `true` to render the string values of this context as Jinja templates against the context merged so far and this context itself, before the main template is rendered and the schema validation happens. A value made of a single expression, such as `{{ replicas + 1 }}`, keeps the type of what it evaluates to while other values are rendered as strings. Templated values can reference each other, in which case they are rendered again until they stop changing, with a limit of 10 passes",
+						},
+						"allow_sensitive": schema.BoolAttribute{
+							Optional:            true,
+							MarkdownDescription: "Set to `true` to load sensitive outputs and resource attributes of `tfstate` contexts as is instead of replacing them with a `(sensitive value)` placeholder",
+						},
+						"encrypted": schema.BoolAttribute{
+							Optional:            true,
+							MarkdownDescription: "Set to `true` when the context is an ASCII armored or binary OpenPGP message to decrypt with the `decryption` settings of the provider before decoding it. A `.asc`, `.gpg` or `.pgp` extension is ignored to infer the type of a `file`. The decrypted content is exposed by the sensitive `sensitive_result` and `sensitive_merged_context` fields, and by the `result` and `merged_context` ones as well unless `sensitive` is set",
+						},
+						"yaml_tags": schema.BoolAttribute{
+							Optional:            true,
+							MarkdownDescription: "Set to `true` to resolve custom tags of `yaml` contexts: `!include path` replaces the node by the content of another YAML document and can be used with merge keys as in `<<: !include base.yaml`, `!file path` loads the raw content of a file, `!env NAME` or `!env [NAME, default]` loads an environment variable with the same rules as the `env` global and `!base64` decodes a base64 encoded string. Relative paths are resolved against the source directory and include cycles are reported as errors",
+						},
+					},
+				},
+			},
+			"set": schema.ListNestedBlock{
+				MarkdownDescription: "Single values to set in the merged context after all `context` blocks and `overrides` entries are applied, in order",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"path": schema.StringAttribute{
+							Required:            true,
+							MarkdownDescription: "Path to the value to set, such as `app.hosts[0].name`. Missing dictionaries and lists are created along the way, and dots or brackets that are part of a key can be escaped with a backslash",
+						},
+						"value": schema.StringAttribute{
 							Required:            true,
-							MarkdownDescription: "A string holding the serialized context",
+							MarkdownDescription: "Value to set, decoded according to `type`",
+						},
+						"type": schema.StringAttribute{
+							Optional:            true,
+							MarkdownDescription: fmt.Sprintf("Type of the value (one of: `%s`). Defaults to `string`", strings.Join(lib.SupportedOverrideTypes, "`,`")),
+							Validators: []validator.String{
+								stringvalidator.OneOf(lib.SupportedOverrideTypes...),
+							},
 						},
 					},
 				},
 			},
 		},
 		Attributes: map[string]schema.Attribute{
+			"overrides": schema.MapAttribute{
+				Optional:            true,
+				MarkdownDescription: "Map of paths, such as `app.image.tag`, to string values to set in the merged context after all `context` blocks are applied, in lexicographic order of the paths",
+				ElementType:         types.StringType,
+			},
 			"template": schema.StringAttribute{
 				Optional:            true,
 				MarkdownDescription: "Inlined or path to the jinja template to render. If the template is passed inlined, any filesystem calls such as using the `include` statement or the `fileset` filter won't work as expected. Deprecated in favor of the `source` block",
@@ -173,6 +279,10 @@ func (d *TemplateDataSource) Schema(ctx context.Context, req datasource.SchemaRe
 				Optional:            true,
 				MarkdownDescription: "Set to `true` to fail on missing items and attribute. Setting this value overrides any value set at the provider level if any",
 			},
+			"strict_parsing": schema.BoolAttribute{
+				Optional:            true,
+				MarkdownDescription: "Set to `true` to fail on duplicate keys, non string keys and unquoted values interpreted differently across YAML versions, such as `NO`, `0755` or `1:30`, in `json` and `yaml` contexts. Errors report the line and column in the offending context. Setting this value overrides any value set at the provider level if any",
+			},
 			"trim_blocks": schema.BoolAttribute{
 				Optional:            true,
 				MarkdownDescription: "Set to `true` the first newline after a block is removed. Setting this value overrides any value set at the provider level if any",
@@ -181,6 +291,14 @@ func (d *TemplateDataSource) Schema(ctx context.Context, req datasource.SchemaRe
 				Optional:            true,
 				MarkdownDescription: "Set to `true` leading spaces and tabs are stripped from the start of a line to a block. Setting this value overrides any value set at the provider level if any",
 			},
+			"sensitive": schema.BoolAttribute{
+				Optional:            true,
+				MarkdownDescription: "Set to `true` to set the `result` and `merged_context` fields to null in favor of the sensitive `sensitive_result` and `sensitive_merged_context` ones, so that the content of encrypted contexts is kept out of plans and outputs",
+			},
+			"frozen_time": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "RFC 3339 timestamp, such as `2024-01-01T00:00:00Z`, returned by the `now` function instead of the current time so that the template renders deterministically. Setting this value overrides any value set at the provider level if any",
+			},
 
 			"timeouts": timeouts.Attributes(ctx, timeouts.Opts{
 				Read: true,
@@ -190,17 +308,44 @@ func (d *TemplateDataSource) Schema(ctx context.Context, req datasource.SchemaRe
 				MarkdownDescription: "Map of JSON schemas to validate against the context. Schemas are tested sequentially in lexicographic order of this map's keys",
 				ElementType:         types.StringType,
 			},
+			"merged_context_format": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: fmt.Sprintf("Format (one of: `%s`) of the `merged_context` field, always serialized with sorted keys. Defaults to `json`", strings.Join(lib.SupportedMergedContextFormats, "`,`")),
+				Validators: []validator.String{
+					stringvalidator.OneOf(lib.SupportedMergedContextFormats...),
+				},
+			},
+			"redact": schema.ListAttribute{
+				Optional:            true,
+				MarkdownDescription: "Paths, such as `db.password`, of values replaced by a `(sensitive value)` placeholder in the `merged_context` field while still being available to the template. A `*` matches any part of a single key or list index, as in `users[*].token`, and `**` matches any number of nested keys, as in `**.password`",
+				ElementType:         types.StringType,
+			},
 			"result": schema.StringAttribute{
 				Computed:            true,
-				MarkdownDescription: "Rendered template with the given context",
+				MarkdownDescription: "Rendered template with the given context, null when `sensitive` is set in favor of `sensitive_result`",
 			},
 			"merged_context": schema.StringAttribute{
 				Computed:            true,
-				MarkdownDescription: "JSON encoded representation of the merged context that has been applied to the template",
+				MarkdownDescription: "JSON encoded representation of the merged context that has been applied to the template, null when `sensitive` is set in favor of `sensitive_merged_context`",
+			},
+			"sensitive_result": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Rendered template with the given context, set when any context is encrypted or when `sensitive` is set",
+			},
+			"sensitive_merged_context": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "JSON encoded representation of the merged context, set when any context is encrypted or when `sensitive` is set",
+			},
+			"inputs": schema.MapAttribute{
+				Computed:            true,
+				MarkdownDescription: "Map of the files read to build the context, including those read through the `!include` and `!file` YAML tags, keyed by their path relative to the source directory and holding the sha256 of their content",
+				ElementType:         types.StringType,
 			},
 			"id": schema.StringAttribute{
 				Computed:            true,
-				MarkdownDescription: "The sha256 of the `result` field",
+				MarkdownDescription: "The sha256 of the `result` field, or of the template and of the contexts as given before decryption when any context is encrypted or when `sensitive` is set, so that it reveals nothing of `sensitive_result`",
 			},
 		},
 	}
@@ -234,7 +379,17 @@ func (t *TemplateDataSource) Read(ctx context.Context, req datasource.ReadReques
 		return
 	}
 
-	result, values, err := lib.Render(renderContext)
+	expandedValues, inputs, err := lib.ExpandFiles(renderContext.Values, renderContext.Source.Directory)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to read context files",
+			fmt.Sprintf("Loading files referenced by the context returned an error: %s", err.Error()),
+		)
+		return
+	}
+	renderContext.Values = expandedValues
+
+	result, values, included, err := lib.Render(renderContext)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to render",
@@ -242,9 +397,36 @@ func (t *TemplateDataSource) Read(ctx context.Context, req datasource.ReadReques
 		)
 		return
 	}
+	for path, signature := range included {
+		inputs[path] = signature
+	}
+	// Decrypted contents are always exposed by the sensitive fields, while the plain ones are only hidden on demand
+	// so that encrypting a context does not break the configurations reading them
+	hidden := data.Sensitive.ValueBool()
+	sensitive := hidden
+	for _, value := range renderContext.Values {
+		sensitive = sensitive || value.Encrypted
+	}
 	data.Result = types.StringValue(string(result))
+	data.SensitiveResult = types.StringNull()
+	if sensitive {
+		data.SensitiveResult = types.StringValue(string(result))
+	}
+	if hidden {
+		data.Result = types.StringNull()
+	}
+	// The hash of a sensitive result would let its content be guessed, so sensitive renders are identified by the
+	// hash of the template and of the contexts instead, encrypted contexts being hashed before decryption
+	identity := result
+	if sensitive {
+		identity = []byte(renderContext.Source.Template)
+		for _, value := range renderContext.Values {
+			sum := sha256.Sum256(value.Data)
+			identity = append(identity, sum[:]...)
+		}
+	}
 	signature := sha256.New()
-	if _, err := signature.Write(result); err != nil {
+	if _, err := signature.Write(identity); err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to compute ID from result",
 			fmt.Sprintf("trying to compute sha256 of the rendering result failed: %s", err.Error()),
@@ -253,7 +435,14 @@ func (t *TemplateDataSource) Read(ctx context.Context, req datasource.ReadReques
 	}
 	data.ID = types.StringValue(hex.EncodeToString(signature.Sum(nil)))
 
-	merged_context, err := json.Marshal(values)
+	redact := []string{}
+	if !data.Redact.IsNull() && !data.Redact.IsUnknown() {
+		resp.Diagnostics.Append(data.Redact.ElementsAs(ctx, &redact, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	merged_context, err := lib.EncodeMergedContext(values, data.MergedContextFormat.ValueString(), redact)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to build `merged_context` field",
@@ -261,7 +450,21 @@ func (t *TemplateDataSource) Read(ctx context.Context, req datasource.ReadReques
 		)
 		return
 	}
-	data.MergedContext = types.StringValue(string(merged_context))
+	data.MergedContext = types.StringValue(merged_context)
+	data.SensitiveMergedContext = types.StringNull()
+	if sensitive {
+		data.SensitiveMergedContext = types.StringValue(merged_context)
+	}
+	if hidden {
+		data.MergedContext = types.StringNull()
+	}
+
+	inputsValue, diagnostics := types.MapValueFrom(ctx, types.StringType, inputs)
+	resp.Diagnostics.Append(diagnostics...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.Inputs = inputsValue
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
@@ -272,6 +475,11 @@ func (t *TemplateDataSource) parseRenderContext(ctx context.Context, data Templa
 		return nil
 	}
 
+	overrides := t.parseOverrides(ctx, data, resp)
+	if resp.Diagnostics.HasError() {
+		return nil
+	}
+
 	schemas := t.parseSchemas(ctx, data, resp)
 	if resp.Diagnostics.HasError() {
 		return nil
@@ -297,11 +505,46 @@ func (t *TemplateDataSource) parseRenderContext(ctx context.Context, data Templa
 		Source:        source,
 		Schemas:       schemas,
 		Values:        values,
+		Overrides:     overrides,
 		Configuration: configuration,
 		Timeout:       timeout,
 	}
 }
 
+func (t *TemplateDataSource) parseOverrides(ctx context.Context, data TemplateDataSourceModel, resp *datasource.ReadResponse) []lib.Override {
+	overrides := []lib.Override{}
+	if !data.Overrides.IsNull() && !data.Overrides.IsUnknown() {
+		values := make(map[string]string)
+		resp.Diagnostics.Append(data.Overrides.ElementsAs(ctx, &values, false)...)
+		if resp.Diagnostics.HasError() {
+			return nil
+		}
+		paths := make([]string, 0, len(values))
+		for path := range values {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			overrides = append(overrides, lib.Override{Path: path, Value: values[path]})
+		}
+	}
+	if !data.Set.IsNull() && !data.Set.IsUnknown() {
+		var sets []SetModel
+		resp.Diagnostics.Append(data.Set.ElementsAs(ctx, &sets, false)...)
+		if resp.Diagnostics.HasError() {
+			return nil
+		}
+		for _, set := range sets {
+			overrides = append(overrides, lib.Override{
+				Path:  set.Path.ValueString(),
+				Value: set.Value.ValueString(),
+				Type:  set.Type.ValueString(),
+			})
+		}
+	}
+	return overrides
+}
+
 func (t *TemplateDataSource) parseSchemas(ctx context.Context, data TemplateDataSourceModel, resp *datasource.ReadResponse) map[string]json.RawMessage {
 	stringSchemas := make(map[string]string)
 	resp.Diagnostics.Append(data.Validation.ElementsAs(ctx, &stringSchemas, false)...)
@@ -321,12 +564,27 @@ func (t *TemplateDataSource) parseConfiguration(ctx context.Context, data Templa
 	if !data.StrictUndefined.IsNull() && !data.StrictUndefined.IsUnknown() {
 		t.Configuration.StrictUndefined = data.StrictUndefined.ValueBool()
 	}
+	if !data.StrictParsing.IsNull() && !data.StrictParsing.IsUnknown() {
+		t.Configuration.StrictParsing = data.StrictParsing.ValueBool()
+	}
 	if !data.LeftStripBlocks.IsNull() && !data.LeftStripBlocks.IsUnknown() {
 		t.Configuration.LeftStripBlocks = data.LeftStripBlocks.ValueBool()
 	}
 	if !data.TrimBlocks.IsNull() && !data.TrimBlocks.IsUnknown() {
 		t.Configuration.TrimBlocks = data.TrimBlocks.ValueBool()
 	}
+	if !data.FrozenTime.IsNull() && !data.FrozenTime.IsUnknown() {
+		frozenTime, err := time.Parse(time.RFC3339, data.FrozenTime.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("frozen_time"),
+				"Invalid frozen time",
+				fmt.Sprintf("%s is not a valid RFC 3339 timestamp", data.FrozenTime.ValueString()),
+			)
+			return t.Configuration
+		}
+		t.Configuration.FrozenTime = frozenTime
+	}
 	if !data.Delimiters.IsNull() && !data.Delimiters.IsUnknown() {
 		var delimiters jinjaDelimitersModel
 		resp.Diagnostics.Append(data.Delimiters.As(ctx, &delimiters, basetypes.ObjectAsOptions{})...)
@@ -365,9 +623,49 @@ func (t *TemplateDataSource) parseValues(ctx context.Context, data TemplateDataS
 		}
 		values := make([]lib.Values, len(contexts))
 		for index, context := range contexts {
+			if context.Type.IsNull() && context.File.IsNull() {
+				resp.Diagnostics.AddAttributeError(
+					path.Root("context").AtListIndex(index).AtName("type"),
+					"Missing context type",
+					"The type of a context must be set when passing its data inline",
+				)
+				return nil
+			}
+			if context.Data.IsNull() && context.File.IsNull() && context.Type.ValueString() != string(lib.FormatEnvironment) {
+				resp.Diagnostics.AddAttributeError(
+					path.Root("context").AtListIndex(index),
+					"Missing context data",
+					fmt.Sprintf("Either `data` or `file` must be set for contexts of type %s", context.Type.ValueString()),
+				)
+				return nil
+			}
+			if context.Type.ValueString() == string(lib.FormatEnvironment) && context.Prefix.ValueString() == "" {
+				resp.Diagnostics.AddAttributeError(
+					path.Root("context").AtListIndex(index).AtName("prefix"),
+					"Missing environment prefix",
+					"A non-empty `prefix` must be set for contexts of type environment, so that only the environment variables meant for the template are loaded",
+				)
+				return nil
+			}
 			values[index] = lib.Values{
 				Type: context.Type.ValueString(),
 				Data: []byte(context.Data.ValueString()),
+				File: context.File.ValueString(),
+				Key:  context.Key.ValueString(),
+				Tabular: lib.TabularOptions{
+					Delimiter:  context.Delimiter.ValueString(),
+					Header:     context.Header.IsNull() || context.Header.ValueBool(),
+					InferTypes: context.InferTypes.ValueBool(),
+					IndexBy:    context.IndexBy.ValueString(),
+				},
+				Environment: lib.EnvironmentOptions{
+					Prefix:    context.Prefix.ValueString(),
+					Separator: context.Separator.ValueString(),
+				},
+				AllowSensitive: context.AllowSensitive.ValueBool(),
+				Render:         context.Render.ValueBool(),
+				YAMLTags:       context.YAMLTags.ValueBool(),
+				Encrypted:      context.Encrypted.ValueBool(),
 			}
 		}
 		return values
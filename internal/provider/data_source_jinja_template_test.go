@@ -1,11 +1,18 @@
 package provider_test
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
 	"os"
 	"path"
 	"strconv"
+	"strings"
 
 	"github.com/MakeNowJust/heredoc"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 
 	. "github.com/onsi/ginkgo/v2"
 )
@@ -288,6 +295,57 @@ var _ = Context("data \"jinja_template\" \"test\" { ... }", func() {
 		})
 	})
 
+	Context("when using `frozen_time`", func() {
+		BeforeEach(func() {
+			*terraformCode = heredoc.Doc(`
+				data "jinja_template" "test" {
+					frozen_time = "2024-03-05T14:07:09+02:00"
+					context {
+						type   = "yaml"
+						data   = "rendered_at: '{{ now() }}'"
+						render = true
+					}
+					source {
+						template  = "{{ now() }} {{ now(format='%Y-%m-%d') }} {{ rendered_at }}"
+						directory = path.module
+					}
+				}
+			`)
+		})
+		itShouldSetTheExpectedResult(terraformCode, "2024-03-05T12:07:09Z 2024-03-05 2024-03-05T12:07:09Z")
+
+		Context("when it is set at the provider level", func() {
+			BeforeEach(func() {
+				*terraformCode = heredoc.Doc(`
+					provider jinja {
+						frozen_time = "2024-03-05T14:07:09Z"
+					}
+					data "jinja_template" "test" {
+						source {
+							template  = "{{ now() | timeadd('1h') }}"
+							directory = path.module
+						}
+					}
+				`)
+			})
+			itShouldSetTheExpectedResult(terraformCode, "2024-03-05T15:07:09Z")
+		})
+		Context("when it is not a valid RFC 3339 timestamp", func() {
+			BeforeEach(func() {
+				*terraformCode = heredoc.Doc(`
+					data "jinja_template" "test" {
+						frozen_time = "2024-03-05"
+						source {
+							template  = "{{ now() }}"
+							directory = path.module
+						}
+					}
+				`)
+			})
+			itShouldFailToRender(terraformCode, "2024-03-05 is not a valid RFC 3339 timestamp")
+		})
+	})
+
 	Context("when setting different `delimiters`", func() {
 		BeforeEach(func() {
 			*terraformCode = heredoc.Doc(`
@@ -539,6 +597,1093 @@ var _ = Context("data \"jinja_template\" \"test\" { ... }", func() {
 				value
 			`))
 		})
+		Context("as INI", func() {
+			BeforeEach(func() {
+				*terraformCode = heredoc.Doc(`
+					data "jinja_template" "test" {
+						source {
+							template  = <<-EOF
+								{{ name }}
+								{{ data.string }}
+								{{ data.quoted }}
+								{{ data.multiline }}
+							EOF
+							directory = path.module
+						}
+						context {
+							type = "ini"
+							data = <<-EOF
+								name = root
+								; comment
+								[data]
+								string = str
+								quoted = "first\tsecond"
+								multiline = first line
+								  second line
+							EOF
+						}
+					}
+				`)
+			})
+			itShouldSetTheExpectedResult(terraformCode, heredoc.Doc(`
+				root
+				str
+				first	second
+				first line
+				second line
+			`))
+		})
+		Context("as dotenv", func() {
+			BeforeEach(func() {
+				*terraformCode = heredoc.Doc(`
+					data "jinja_template" "test" {
+						source {
+							template  = <<-EOF
+								{{ PLAIN }}
+								{{ EXPORTED }}
+								{{ LITERAL }}
+								{{ MULTILINE }}
+							EOF
+							directory = path.module
+						}
+						context {
+							type = "dotenv"
+							data = <<-EOF
+								# comment
+								PLAIN=value # comment
+								export EXPORTED=exported
+								LITERAL='$NOT_EXPANDED'
+								MULTILINE="first line
+								second line"
+							EOF
+						}
+					}
+				`)
+			})
+			itShouldSetTheExpectedResult(terraformCode, heredoc.Doc(`
+				value
+				exported
+				$NOT_EXPANDED
+				first line
+				second line
+			`))
+		})
+		Context("as properties", func() {
+			BeforeEach(func() {
+				*terraformCode = heredoc.Doc(`
+					data "jinja_template" "test" {
+						source {
+							template  = <<-EOF
+								{{ host }}
+								{{ port }}
+								{{ continued }}
+							EOF
+							directory = path.module
+						}
+						context {
+							type = "properties"
+							data = <<-EOF
+								# comment
+								host = localhost
+								port: 5432
+								continued = first \
+								    second
+							EOF
+						}
+					}
+				`)
+			})
+			itShouldSetTheExpectedResult(terraformCode, heredoc.Doc(`
+				localhost
+				5432
+				first second
+			`))
+		})
+		Context("as XML", func() {
+			BeforeEach(func() {
+				*terraformCode = heredoc.Doc(`
+					data "jinja_template" "test" {
+						source {
+							template  = <<-EOF
+								{{ project.version }}
+								{{ project["@id"] }}
+								{{ project.modules.module | join(",") }}
+							EOF
+							directory = path.module
+						}
+						context {
+							type = "xml"
+							data = <<-EOF
+								<?xml version="1.0" encoding="UTF-8"?>
+								<project id="example">
+								  <version>1.0.0</version>
+								  <modules>
+								    <module>api</module>
+								    <module>worker</module>
+								  </modules>
+								</project>
+							EOF
+						}
+					}
+				`)
+			})
+			itShouldSetTheExpectedResult(terraformCode, heredoc.Doc(`
+				1.0.0
+				example
+				api,worker
+			`))
+		})
+		Context("as CSV", func() {
+			BeforeEach(func() {
+				*terraformCode = heredoc.Doc(`
+					data "jinja_template" "test" {
+						source {
+							template  = <<-EOF
+								{{ hosts.db.port + 1 }}
+								{{ hosts.web.tls }}
+							EOF
+							directory = path.module
+						}
+						context {
+							type        = "csv"
+							key         = "hosts"
+							index_by    = "name"
+							infer_types = true
+							data        = <<-EOF
+								name,port,tls
+								web,443,true
+								db,5432,false
+							EOF
+						}
+					}
+				`)
+			})
+			itShouldSetTheExpectedResult(terraformCode, heredoc.Doc(`
+				5433
+				True
+			`))
+			Context("when no key is set", func() {
+				BeforeEach(func() {
+					*terraformCode = heredoc.Doc(`
+						data "jinja_template" "test" {
+							source {
+								template  = "{{ hosts }}"
+								directory = path.module
+							}
+							context {
+								type = "csv"
+								data = "name,port\nweb,443"
+							}
+						}
+					`)
+				})
+				itShouldFailToRender(terraformCode, "1st values layer is not a dict and must be loaded under a key")
+			})
+		})
+		Context("as TSV", func() {
+			BeforeEach(func() {
+				*terraformCode = heredoc.Doc(`
+					data "jinja_template" "test" {
+						source {
+							template  = "{% for row in rows %}{{ row | join('=') }};{% endfor %}"
+							directory = path.module
+						}
+						context {
+							type   = "tsv"
+							key    = "rows"
+							header = false
+							data   = "a\t1\nb\t2\n"
+						}
+					}
+				`)
+			})
+			itShouldSetTheExpectedResult(terraformCode, "a=1;b=2;")
+			Context("when inferring types", func() {
+				BeforeEach(func() {
+					*terraformCode = heredoc.Doc(`
+						data "jinja_template" "test" {
+							source {
+								template  = "{{ rows[0].zip }} {{ rows[0].zip is string }} {{ rows[0].count + 1 }} {{ rows[0].ratio * 2 }} {{ rows[0].size }}"
+								directory = path.module
+							}
+							context {
+								type        = "tsv"
+								key         = "rows"
+								infer_types = true
+								data        = "zip\tcount\tratio\tsize\n007\t0\t0.25\t5\" screen\n"
+							}
+						}
+					`)
+				})
+				itShouldSetTheExpectedResult(terraformCode, "007 True 1 0.5 5\" screen")
+			})
+		})
+		Context("as NDJSON", func() {
+			BeforeEach(func() {
+				*terraformCode = heredoc.Doc(`
+					data "jinja_template" "test" {
+						source {
+							template  = "{{ events | length }} {{ events[1].level }}"
+							directory = path.module
+						}
+						context {
+							type = "ndjson"
+							key  = "events"
+							data = <<-EOF
+								{"level": "info", "message": "started"}
+								{"level": "error", "message": "failed"}
+							EOF
+						}
+					}
+				`)
+			})
+			itShouldSetTheExpectedResult(terraformCode, "2 error")
+		})
+		Context("as terraform state", func() {
+			BeforeEach(func() {
+				*terraformCode = heredoc.Doc(`
+					data "jinja_template" "test" {
+						source {
+							template  = <<-EOF
+								{{ state.outputs.endpoint }}
+								{{ state.outputs.password }}
+								{% for resource in state.resources -%}
+								{{ resource.address }}: {{ resource["values"].port }}
+								{% endfor -%}
+							EOF
+							directory = path.module
+						}
+						context {
+							type = "tfstate"
+							key  = "state"
+							data = jsonencode({
+								format_version    = "1.0"
+								terraform_version = "1.6.0"
+								values = {
+									outputs = {
+										endpoint = { sensitive = false, value = "db.internal" }
+										password = { sensitive = true, value = "hunter2" }
+									}
+									root_module = {
+										resources = [{
+											address       = "aws_instance.web[0]"
+											mode          = "managed"
+											type          = "aws_instance"
+											name          = "web"
+											index         = 0
+											provider_name = "registry.terraform.io/hashicorp/aws"
+											values        = { port = 443 }
+										}]
+										child_modules = [{
+											address = "module.db"
+											resources = [{
+												address       = "module.db.aws_instance.primary"
+												mode          = "managed"
+												type          = "aws_instance"
+												name          = "primary"
+												provider_name = "registry.terraform.io/hashicorp/aws"
+												values        = { port = 5432 }
+											}]
+										}]
+									}
+								}
+							})
+						}
+					}
+				`)
+			})
+			itShouldSetTheExpectedResult(terraformCode, heredoc.Doc(`
+				db.internal
+				(sensitive value)
+				aws_instance.web[0]: 443
+				module.db.aws_instance.primary: 5432
+			`))
+			Context("when allowing sensitive values", func() {
+				BeforeEach(func() {
+					*terraformCode = heredoc.Doc(`
+						data "jinja_template" "test" {
+							source {
+								template  = "{{ outputs.password }}"
+								directory = path.module
+							}
+							context {
+								type            = "tfstate"
+								allow_sensitive = true
+								data = jsonencode({
+									format_version = "1.0"
+									values = {
+										outputs = {
+											password = { sensitive = true, value = "hunter2" }
+										}
+									}
+								})
+							}
+						}
+					`)
+				})
+				itShouldSetTheExpectedResult(terraformCode, "hunter2")
+			})
+		})
+		Context("from files", func() {
+			var (
+				directory string
+			)
+			BeforeEach(func() {
+				directory = MustReturn(os.MkdirTemp("", ""))
+				Must(os.MkdirAll(path.Join(directory, "values"), 0700))
+				Must(os.WriteFile(path.Join(directory, "values", "1-base.yaml"), []byte("name: base\nregion: eu\n"), 0600))
+				Must(os.WriteFile(path.Join(directory, "values", "2-override.json"), []byte(`{"name": "override"}`), 0600))
+				Must(os.WriteFile(path.Join(directory, "settings.txt"), []byte("replicas = 3\n"), 0600))
+
+				*terraformCode = heredoc.Doc(`
+					data "jinja_template" "test" {
+						source {
+							template  = "{{ name }} {{ region }} {{ replicas }}"
+							directory = "` + directory + `"
+						}
+						context {
+							file = "values/*"
+						}
+						context {
+							type = "toml"
+							file = "settings.txt"
+						}
+					}
+				`)
+			})
+			AfterEach(func() {
+				os.RemoveAll(directory)
+			})
+			It("should render the expected content and record the files as inputs", func() {
+				resource.UnitTest(GinkgoT(), resource.TestCase{
+					ProtoV6ProviderFactories: testProviderFactory,
+					Steps: []resource.TestStep{
+						{
+							Config: *terraformCode,
+							Check: resource.ComposeTestCheckFunc(
+								resource.TestCheckResourceAttr("data.jinja_template.test", "result", "override eu 3"),
+								resource.TestCheckResourceAttr("data.jinja_template.test", "inputs.%", "3"),
+								resource.TestCheckResourceAttr("data.jinja_template.test", "inputs.settings.txt", "ec6ed013bd8b7872c0bda0b8092c3493445eb9c7d6beacba23d15ef1a95f3bb3"),
+								resource.TestCheckResourceAttrSet("data.jinja_template.test", "inputs.values/1-base.yaml"),
+								resource.TestCheckResourceAttrSet("data.jinja_template.test", "inputs.values/2-override.json"),
+							),
+						},
+					},
+				})
+			})
+			Context("when the type can not be inferred", func() {
+				BeforeEach(func() {
+					*terraformCode = heredoc.Doc(`
+						data "jinja_template" "test" {
+							source {
+								template  = "{{ replicas }}"
+								directory = "` + directory + `"
+							}
+							context {
+								file = "settings.txt"
+							}
+						}
+					`)
+				})
+				itShouldFailToRender(terraformCode, "1st values layer: unable to infer the type of .*settings.txt from its extension, a type must be set")
+			})
+			Context("when no file matches", func() {
+				BeforeEach(func() {
+					*terraformCode = heredoc.Doc(`
+						data "jinja_template" "test" {
+							source {
+								template  = "{{ name }}"
+								directory = "` + directory + `"
+							}
+							context {
+								file = "missing/*.yaml"
+							}
+						}
+					`)
+				})
+				itShouldFailToRender(terraformCode, "1st values layer: no file matches missing/\\*.yaml")
+			})
+			Context("when both data and file are set", func() {
+				BeforeEach(func() {
+					*terraformCode = heredoc.Doc(`
+						data "jinja_template" "test" {
+							source {
+								template  = "{{ name }}"
+								directory = "` + directory + `"
+							}
+							context {
+								type = "yaml"
+								data = "name: inline"
+								file = "values/1-base.yaml"
+							}
+						}
+					`)
+				})
+				itShouldFailToRender(terraformCode, "Invalid Attribute Combination")
+			})
+			Context("when the type of inline data is missing", func() {
+				BeforeEach(func() {
+					*terraformCode = heredoc.Doc(`
+						data "jinja_template" "test" {
+							source {
+								template  = "{{ name }}"
+								directory = "` + directory + `"
+							}
+							context {
+								data = "name: inline"
+							}
+						}
+					`)
+				})
+				itShouldFailToRender(terraformCode, "The type of a context must be set when passing its data inline")
+			})
+		})
+		Context("when nesting layers under a path", func() {
+			BeforeEach(func() {
+				*terraformCode = heredoc.Doc(`
+					data "jinja_template" "test" {
+						source {
+							template  = <<-EOF
+								{{ services | tojson }}
+								{{ regions | join(",") }}
+								{{ annotations["example.com/owner"] }}
+							EOF
+							directory = path.module
+						}
+						context {
+							type = "yaml"
+							data = "web: { port: 443 }"
+							key  = "services"
+						}
+						context {
+							type = "yaml"
+							data = "nginx"
+							key  = "services.web.image"
+						}
+						context {
+							type = "json"
+							data = jsonencode(["eu", "us"])
+							key  = "regions"
+						}
+						context {
+							type = "json"
+							data = jsonencode("team")
+							key  = "annotations.example\\.com/owner"
+						}
+					}
+				`)
+			})
+			itShouldSetTheExpectedResult(terraformCode, heredoc.Doc(`
+				{"web":{"image":"nginx","port":443}}
+				eu,us
+				team
+			`))
+			Context("when the path holds an empty key", func() {
+				BeforeEach(func() {
+					*terraformCode = heredoc.Doc(`
+						data "jinja_template" "test" {
+							source {
+								template  = "{{ a }}"
+								directory = path.module
+							}
+							context {
+								type = "json"
+								data = "1"
+								key  = "a..b"
+							}
+						}
+					`)
+				})
+				itShouldFailToRender(terraformCode, "1st values layer can not be loaded under key a..b: path a..b holds an empty key")
+			})
+		})
+		Context("when overriding values", func() {
+			BeforeEach(func() {
+				*terraformCode = heredoc.Doc(`
+					data "jinja_template" "test" {
+						source {
+							template  = "{{ app | tojson }}"
+							directory = path.module
+						}
+						context {
+							type = "yaml"
+							data = <<-EOF
+								app:
+								  replicas: 1
+								  hosts: [a, b]
+								  image:
+								    tag: v1
+							EOF
+						}
+						overrides = {
+							"app.image.tag" = "v2"
+							"app.hosts[0]"  = "z"
+						}
+						set {
+							path  = "app.replicas"
+							value = "3"
+							type  = "int"
+						}
+						set {
+							path  = "app.labels.example\\.com/team"
+							value = "{\"name\": \"core\", \"oncall\": true}"
+							type  = "json"
+						}
+						set {
+							path  = "app.image.tag"
+							value = "v3"
+						}
+					}
+				`)
+			})
+			itShouldSetTheExpectedResult(terraformCode, `{"hosts":["z","b"],"image":{"tag":"v3"},"labels":{"example.com/team":{"name":"core","oncall":true}},"replicas":3}`)
+			Context("when the path traverses a scalar", func() {
+				BeforeEach(func() {
+					*terraformCode = heredoc.Doc(`
+						data "jinja_template" "test" {
+							source {
+								template  = "{{ app }}"
+								directory = path.module
+							}
+							context {
+								type = "yaml"
+								data = "app: { replicas: 1 }"
+							}
+							set {
+								path  = "app.replicas.count"
+								value = "3"
+							}
+						}
+					`)
+				})
+				itShouldFailToRender(terraformCode, "failed to apply overrides: can not traverse app.replicas.count: app.replicas holds the scalar 1 instead of a dict")
+			})
+			Context("when the value does not match its type", func() {
+				BeforeEach(func() {
+					*terraformCode = heredoc.Doc(`
+						data "jinja_template" "test" {
+							source {
+								template  = "{{ app }}"
+								directory = path.module
+							}
+							set {
+								path  = "app.replicas"
+								value = "three"
+								type  = "int"
+							}
+						}
+					`)
+				})
+				itShouldFailToRender(terraformCode, `failed to decode value of app.replicas: "three" is not an integer`)
+			})
+			Context("when the index is out of range", func() {
+				BeforeEach(func() {
+					*terraformCode = heredoc.Doc(`
+						data "jinja_template" "test" {
+							source {
+								template  = "{{ hosts }}"
+								directory = path.module
+							}
+							context {
+								type = "json"
+								data = jsonencode({ hosts = ["a"] })
+							}
+							overrides = {
+								"hosts[3]" = "d"
+							}
+						}
+					`)
+				})
+				itShouldFailToRender(terraformCode, "index 3 of hosts is out of range for a list of length 1")
+			})
+		})
+		Context("when rendering a context", func() {
+			BeforeEach(func() {
+				*terraformCode = heredoc.Doc(`
+					data "jinja_template" "test" {
+						source {
+							template  = "{{ fqdn }} {{ urls | join(',') }} {{ banner }}"
+							directory = path.module
+						}
+						context {
+							type = "yaml"
+							data = "domain: example.com"
+						}
+						context {
+							type   = "yaml"
+							render = true
+							data   = <<-EOF
+								banner: "{{ urls[1] | replace('https://', '') | upper }}"
+								fqdn: "api.{{ domain }}"
+								urls:
+								  - "http://{{ fqdn }}"
+								  - "https://{{ fqdn }}"
+							EOF
+						}
+						validation = {
+							"schema" = jsonencode({
+								type = "object"
+								properties = {
+									fqdn = { pattern = "^api\\.example\\.com$" }
+								}
+							})
+						}
+					}
+				`)
+			})
+			itShouldSetTheExpectedResult(terraformCode, "api.example.com http://api.example.com,https://api.example.com API.EXAMPLE.COM")
+			Context("when values reference each other in a cycle", func() {
+				BeforeEach(func() {
+					*terraformCode = heredoc.Doc(`
+						data "jinja_template" "test" {
+							source {
+								template  = "{{ a }}"
+								directory = path.module
+							}
+							context {
+								type   = "json"
+								render = true
+								data   = jsonencode({ a = "{{ b }}", b = "{{ a }}" })
+							}
+						}
+					`)
+				})
+				itShouldFailToRender(terraformCode, "failed to render 1st values layer: cycle detected in templated values: a, b")
+			})
+			Context("when a value is made of a single expression", func() {
+				BeforeEach(func() {
+					*terraformCode = heredoc.Doc(`
+						data "jinja_template" "test" {
+							source {
+								template  = "{{ replicas + 1 }} {{ label }} {{ hosts | length }}"
+								directory = path.module
+							}
+							context {
+								type = "yaml"
+								data = "base: 41"
+							}
+							context {
+								type   = "yaml"
+								render = true
+								data   = <<-EOF
+									replicas: "{{ base + 1 }}"
+									label: "replicas={{ base + 1 }}"
+									hosts: "{{ ['a', 'b'] }}"
+								EOF
+							}
+							validation = {
+								"schema" = jsonencode({
+									type = "object"
+									properties = {
+										replicas = { type = "integer" }
+										label    = { type = "string" }
+										hosts    = { type = "array" }
+									}
+								})
+							}
+						}
+					`)
+				})
+				itShouldSetTheExpectedResult(terraformCode, "43 replicas=42 2")
+			})
+			Context("when a value references itself", func() {
+				BeforeEach(func() {
+					*terraformCode = heredoc.Doc(`
+						data "jinja_template" "test" {
+							source {
+								template  = "{{ a }}"
+								directory = path.module
+							}
+							context {
+								type   = "json"
+								render = true
+								data   = jsonencode({ a = "x{{ a }}" })
+							}
+						}
+					`)
+				})
+				itShouldFailToRender(terraformCode, "failed to render 1st values layer: cycle detected in templated values: a")
+			})
+		})
+		Context("when resolving YAML tags", func() {
+			var (
+				directory string
+			)
+			BeforeEach(func() {
+				directory = MustReturn(os.MkdirTemp("", ""))
+				Must(os.WriteFile(path.Join(directory, "base.yaml"), []byte("name: base\nport: 80\n"), 0600))
+				Must(os.WriteFile(path.Join(directory, "cert.pem"), []byte("-----BEGIN CERTIFICATE-----"), 0600))
+				Must(os.Setenv("JINJA_PROVIDER_YAML_TAG", "from-env"))
+
+				*terraformCode = heredoc.Doc(`
+					data "jinja_template" "test" {
+						source {
+							template  = "{{ name }} {{ port }} {{ cert }} {{ env }} {{ fallback }} {{ decoded }}"
+							directory = "` + directory + `"
+						}
+						context {
+							type      = "yaml"
+							yaml_tags = true
+							data      = <<-EOF
+								<<: !include base.yaml
+								name: override
+								cert: !file cert.pem
+								env: !env JINJA_PROVIDER_YAML_TAG
+								fallback: !env [JINJA_PROVIDER_UNSET_YAML_TAG, default]
+								decoded: !base64 aGVsbG8=
+							EOF
+						}
+					}
+				`)
+			})
+			AfterEach(func() {
+				os.RemoveAll(directory)
+				os.Unsetenv("JINJA_PROVIDER_YAML_TAG")
+			})
+			itShouldSetTheExpectedResult(terraformCode, "override 80 -----BEGIN CERTIFICATE----- from-env default hello")
+			It("should record the files read through tags as inputs", func() {
+				resource.UnitTest(GinkgoT(), resource.TestCase{
+					ProtoV6ProviderFactories: testProviderFactory,
+					Steps: []resource.TestStep{
+						{
+							Config: *terraformCode,
+							Check: resource.ComposeTestCheckFunc(
+								resource.TestCheckResourceAttr("data.jinja_template.test", "inputs.%", "2"),
+								resource.TestCheckResourceAttr("data.jinja_template.test", "inputs.base.yaml", fmt.Sprintf("%x", sha256.Sum256([]byte("name: base\nport: 80\n")))),
+								resource.TestCheckResourceAttr("data.jinja_template.test", "inputs.cert.pem", fmt.Sprintf("%x", sha256.Sum256([]byte("-----BEGIN CERTIFICATE-----")))),
+							),
+						},
+					},
+				})
+			})
+			Context("when files include each other", func() {
+				BeforeEach(func() {
+					Must(os.WriteFile(path.Join(directory, "a.yaml"), []byte("b: !include b.yaml\n"), 0600))
+					Must(os.WriteFile(path.Join(directory, "b.yaml"), []byte("a: !include a.yaml\n"), 0600))
+
+					*terraformCode = heredoc.Doc(`
+						data "jinja_template" "test" {
+							source {
+								template  = "{{ b }}"
+								directory = "` + directory + `"
+							}
+							context {
+								file      = "a.yaml"
+								yaml_tags = true
+							}
+						}
+					`)
+				})
+				itShouldFailToRender(terraformCode, "include cycle detected: a.yaml -> b.yaml -> a.yaml")
+			})
+		})
+		Context("when parsing strictly", func() {
+			BeforeEach(func() {
+				*terraformCode = heredoc.Doc(`
+					data "jinja_template" "test" {
+						source {
+							template  = "{{ country }} {{ mode }}"
+							directory = path.module
+						}
+						context {
+							type = "yaml"
+							data = <<-EOF
+								country: "NO"
+								mode: !!str 0755
+							EOF
+						}
+						strict_parsing = true
+					}
+				`)
+			})
+			itShouldSetTheExpectedResult(terraformCode, "NO 0755")
+			Context("when an unquoted value is ambiguous", func() {
+				BeforeEach(func() {
+					*terraformCode = heredoc.Doc(`
+						data "jinja_template" "test" {
+							source {
+								template  = "{{ country }}"
+								directory = path.module
+							}
+							context {
+								type = "yaml"
+								data = <<-EOF
+									name: example
+									country: NO
+								EOF
+							}
+							strict_parsing = true
+						}
+					`)
+				})
+				itShouldFailToRender(terraformCode, "1st values layer: failed to unmarshal YAML context: line 2, column 10: unquoted value NO is ambiguous and must be quoted")
+			})
+			Context("when a JSON context holds duplicate keys", func() {
+				BeforeEach(func() {
+					*terraformCode = heredoc.Doc(`
+						data "jinja_template" "test" {
+							source {
+								template  = "{{ name }}"
+								directory = path.module
+							}
+							context {
+								type = "json"
+								data = "{\"name\": \"first\", \"name\": \"second\"}"
+							}
+							strict_parsing = true
+						}
+					`)
+				})
+				itShouldFailToRender(terraformCode, "1st values layer: failed to decode JSON context: line 1, column 19: key \"name\" is already defined at line 1, column 2")
+			})
+			Context("when a key is not a string", func() {
+				BeforeEach(func() {
+					*terraformCode = heredoc.Doc(`
+						data "jinja_template" "test" {
+							source {
+								template  = "{{ ports }}"
+								directory = path.module
+							}
+							context {
+								type = "yaml"
+								data = <<-EOF
+									ports:
+									  80: http
+								EOF
+							}
+							strict_parsing = true
+						}
+					`)
+				})
+				itShouldFailToRender(terraformCode, "1st values layer: failed to unmarshal YAML context: line 2, column 3: key 80 is not a string")
+			})
+		})
+		Context("when decrypting a context", func() {
+			BeforeEach(func() {
+				message := new(bytes.Buffer)
+				armored := MustReturn(armor.Encode(message, "PGP MESSAGE", nil))
+				plaintext := MustReturn(openpgp.SymmetricallyEncrypt(armored, []byte("passphrase"), nil, nil))
+				MustReturn(plaintext.Write([]byte("password: hunter2\n")))
+				Must(plaintext.Close())
+				Must(armored.Close())
+				Must(os.Setenv("JINJA_PROVIDER_PGP_PASSPHRASE", "passphrase"))
+
+				*terraformCode = heredoc.Doc(`
+					provider "jinja" {
+						decryption {
+							passphrase_env = "JINJA_PROVIDER_PGP_PASSPHRASE"
+						}
+					}
+					data "jinja_template" "test" {
+						source {
+							template  = "{{ user }}:{{ password }}"
+							directory = path.module
+						}
+						context {
+							type = "yaml"
+							data = "user: admin"
+						}
+						context {
+							type      = "yaml"
+							encrypted = true
+							data      = ` + strconv.Quote(message.String()) + `
+						}
+					}
+				`)
+			})
+			AfterEach(func() {
+				os.Unsetenv("JINJA_PROVIDER_PGP_PASSPHRASE")
+			})
+			It("should set both the plain and the sensitive fields", func() {
+				resource.UnitTest(GinkgoT(), resource.TestCase{
+					ProtoV6ProviderFactories: testProviderFactory,
+					Steps: []resource.TestStep{
+						{
+							Config: *terraformCode,
+							Check: resource.ComposeTestCheckFunc(
+								resource.TestCheckResourceAttr("data.jinja_template.test", "sensitive_result", "admin:hunter2"),
+								resource.TestCheckResourceAttr("data.jinja_template.test", "sensitive_merged_context", `{"password":"hunter2","user":"admin"}`),
+								resource.TestCheckResourceAttr("data.jinja_template.test", "result", "admin:hunter2"),
+								resource.TestCheckResourceAttr("data.jinja_template.test", "merged_context", `{"password":"hunter2","user":"admin"}`),
+								resource.TestCheckResourceAttrWith("data.jinja_template.test", "id", func(id string) error {
+									if id == "" || id == fmt.Sprintf("%x", sha256.Sum256([]byte("admin:hunter2"))) {
+										return fmt.Errorf("id %q is not derived from the inputs of the render", id)
+									}
+									return nil
+								}),
+							),
+						},
+					},
+				})
+			})
+			Context("when only exposing sensitive fields", func() {
+				BeforeEach(func() {
+					*terraformCode = strings.Replace(*terraformCode, "data \"jinja_template\" \"test\" {\n", "data \"jinja_template\" \"test\" {\n\tsensitive = true\n", 1)
+				})
+				It("should set the plain fields to null", func() {
+					resource.UnitTest(GinkgoT(), resource.TestCase{
+						ProtoV6ProviderFactories: testProviderFactory,
+						Steps: []resource.TestStep{
+							{
+								Config: *terraformCode,
+								Check: resource.ComposeTestCheckFunc(
+									resource.TestCheckResourceAttr("data.jinja_template.test", "sensitive_result", "admin:hunter2"),
+									resource.TestCheckResourceAttr("data.jinja_template.test", "sensitive_merged_context", `{"password":"hunter2","user":"admin"}`),
+									resource.TestCheckNoResourceAttr("data.jinja_template.test", "result"),
+									resource.TestCheckNoResourceAttr("data.jinja_template.test", "merged_context"),
+								),
+							},
+						},
+					})
+				})
+			})
+			Context("when the passphrase is incorrect", func() {
+				BeforeEach(func() {
+					Must(os.Setenv("JINJA_PROVIDER_PGP_PASSPHRASE", "incorrect"))
+				})
+				itShouldFailToRender(terraformCode, "failed to decrypt 2nd values layer: failed to decrypt message: the passphrase is missing or incorrect")
+			})
+		})
+		Context("when loading the environment", func() {
+			BeforeEach(func() {
+				Must(os.Setenv("JINJA_PROVIDER_APP_DB__HOST", "localhost"))
+				Must(os.Setenv("JINJA_PROVIDER_APP_DB__PORT", "5432"))
+				Must(os.Setenv("JINJA_PROVIDER_APP_LOG_LEVEL", "info"))
+
+				*terraformCode = heredoc.Doc(`
+					data "jinja_template" "test" {
+						source {
+							template  = "{{ db.host }}:{{ db.port }} {{ log_level }} {{ name }}"
+							directory = path.module
+						}
+						context {
+							type = "yaml"
+							data = "name: app"
+						}
+						context {
+							type   = "environment"
+							prefix = "JINJA_PROVIDER_APP_"
+						}
+						validation = {
+							"schema" = jsonencode({
+								type = "object"
+								properties = {
+									db = {
+										type = "object"
+										properties = {
+											port = { type = "integer" }
+										}
+									}
+								}
+							})
+						}
+					}
+				`)
+			})
+			AfterEach(func() {
+				os.Unsetenv("JINJA_PROVIDER_APP_DB__HOST")
+				os.Unsetenv("JINJA_PROVIDER_APP_DB__PORT")
+				os.Unsetenv("JINJA_PROVIDER_APP_LOG_LEVEL")
+			})
+			itShouldSetTheExpectedResult(terraformCode, "localhost:5432 info app")
+			Context("when the data of another type is missing", func() {
+				BeforeEach(func() {
+					*terraformCode = heredoc.Doc(`
+						data "jinja_template" "test" {
+							source {
+								template  = "{{ name }}"
+								directory = path.module
+							}
+							context {
+								type = "yaml"
+							}
+						}
+					`)
+				})
+				itShouldFailToRender(terraformCode, "Either `data` or `file` must be set for contexts of type yaml")
+			})
+			Context("when the prefix is missing", func() {
+				BeforeEach(func() {
+					*terraformCode = heredoc.Doc(`
+						data "jinja_template" "test" {
+							source {
+								template  = "{{ name }}"
+								directory = path.module
+							}
+							context {
+								type = "environment"
+							}
+						}
+					`)
+				})
+				itShouldFailToRender(terraformCode, "A non-empty `prefix` must be set for contexts of type environment")
+			})
+			Context("when the prefix is empty", func() {
+				BeforeEach(func() {
+					*terraformCode = heredoc.Doc(`
+						data "jinja_template" "test" {
+							source {
+								template  = "{{ name }}"
+								directory = path.module
+							}
+							context {
+								type   = "environment"
+								prefix = ""
+							}
+						}
+					`)
+				})
+				itShouldFailToRender(terraformCode, "A non-empty `prefix` must be set for contexts of type environment")
+			})
+		})
+		Context("when formatting and redacting the merged context", func() {
+			BeforeEach(func() {
+				*terraformCode = heredoc.Doc(`
+					data "jinja_template" "test" {
+						source {
+							template  = "{{ db.password }} {{ users[0].token }}"
+							directory = path.module
+						}
+						context {
+							type = "yaml"
+							data = <<-EOF
+								name: app
+								db:
+								  host: localhost
+								  password: hunter2
+								users:
+								  - name: admin
+								    token: secret
+							EOF
+						}
+						merged_context_format = "yaml"
+						redact                = ["**.password", "users[*].token"]
+					}
+				`)
+			})
+			It("should render with the original values and redact the merged context", func() {
+				resource.UnitTest(GinkgoT(), resource.TestCase{
+					ProtoV6ProviderFactories: testProviderFactory,
+					Steps: []resource.TestStep{
+						{
+							Config: *terraformCode,
+							Check: resource.ComposeTestCheckFunc(
+								resource.TestCheckResourceAttr("data.jinja_template.test", "result", "hunter2 secret"),
+								resource.TestCheckResourceAttr("data.jinja_template.test", "merged_context", heredoc.Doc(`
+									db:
+									  host: localhost
+									  password: (sensitive value)
+									name: app
+									users:
+									  - name: admin
+									    token: (sensitive value)
+								`)),
+							),
+						},
+					},
+				})
+			})
+		})
 		Context("when passing multiple layers", func() {
 			BeforeEach(func() {
 				*terraformCode = heredoc.Doc(`
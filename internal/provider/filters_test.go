@@ -222,6 +222,96 @@ var _ = Context("filters", func() {
 			itShouldFailToRender(terraformCode, "thrown")
 		})
 	})
+	Context("cidrsubnet", func() {
+		BeforeEach(func() {
+			*template = `{{- "172.16.0.0/12" | cidrsubnet(4, 2) }} {{ "fd00:fd12:3456:7890::/56" | cidrsubnet(16, 162) -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, "172.18.0.0/16 fd00:fd12:3456:7800:a200::/72")
+		Context("when the prefix can not be extended", func() {
+			BeforeEach(func() {
+				*template = `{{- "10.1.0.0/16" | cidrsubnet(17, 1) -}}`
+			})
+			itShouldFailToRender(terraformCode, "insufficient address space to extend prefix of 16 by 17")
+		})
+		Context("when the input is not a CIDR notation", func() {
+			BeforeEach(func() {
+				*template = `{{- "10.1.0.0" | cidrsubnet(8, 1) -}}`
+			})
+			itShouldFailToRender(terraformCode, "10.1.0.0 is not a valid CIDR notation")
+		})
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- "thrown" | fail | cidrsubnet(8, 1) -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
+	Context("cidrsubnets", func() {
+		BeforeEach(func() {
+			*template = `{{- "10.1.0.0/16" | cidrsubnets(4, 4, 8, 4) -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, "['10.1.0.0/20', '10.1.16.0/20', '10.1.32.0/24', '10.1.48.0/20']")
+		Context("when there is not enough address space", func() {
+			BeforeEach(func() {
+				*template = `{{- "10.1.0.0/30" | cidrsubnets(1, 1, 1) -}}`
+			})
+			itShouldFailToRender(terraformCode, "not enough remaining address space for a subnet with a prefix of 31 bits after 10.1.0.2/31")
+		})
+		Context("when an argument is not an integer", func() {
+			BeforeEach(func() {
+				*template = `{{- "10.1.0.0/16" | cidrsubnets(4, "4") -}}`
+			})
+			itShouldFailToRender(terraformCode, "2nd argument 4 is not an integer")
+		})
+	})
+	Context("cidrhost", func() {
+		BeforeEach(func() {
+			*template = `{{- "10.12.112.0/20" | cidrhost(268) }} {{ "10.12.112.0/20" | cidrhost(-2) -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, "10.12.113.12 10.12.127.254")
+		Context("when the host number is out of the network", func() {
+			BeforeEach(func() {
+				*template = `{{- "10.1.0.0/16" | cidrhost(65536) -}}`
+			})
+			itShouldFailToRender(terraformCode, "prefix of 16 does not accommodate a host numbered 65536")
+		})
+	})
+	Context("cidrnetmask", func() {
+		BeforeEach(func() {
+			*template = `{{- "172.16.0.0/12" | cidrnetmask -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, "255.240.0.0")
+		Context("when the network is an IPv6 one", func() {
+			BeforeEach(func() {
+				*template = `{{- "fd00::/8" | cidrnetmask -}}`
+			})
+			itShouldFailToRender(terraformCode, "only IPv4 networks are supported")
+		})
+	})
+	Context("cidr_contains", func() {
+		BeforeEach(func() {
+			*template = `{{- "10.0.0.0/8" | cidr_contains("10.1.0.0/16") }} {{ "10.0.0.0/8" | cidr_contains("11.0.0.1") -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, "True False")
+		Context("when the argument is not a valid address", func() {
+			BeforeEach(func() {
+				*template = `{{- "10.0.0.0/8" | cidr_contains("foo") -}}`
+			})
+			itShouldFailToRender(terraformCode, "foo is neither a valid IP address nor a valid CIDR notation")
+		})
+	})
+	Context("cidr_merge", func() {
+		BeforeEach(func() {
+			*template = `{{- ["10.0.0.0/25", "10.0.0.128/25", "10.0.1.0/24", "fd00::1", "192.168.0.1"] | cidr_merge -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, "['10.0.0.0/23', '192.168.0.1/32', 'fd00::1/128']")
+		Context("when the input is not a list", func() {
+			BeforeEach(func() {
+				*template = `{{- "10.0.0.0/8" | cidr_merge -}}`
+			})
+			itShouldFailToRender(terraformCode, "10.0.0.0/8 is not a list")
+		})
+	})
 	Context("dirname", func() {
 		BeforeEach(func() {
 			*template = `{{- "test/folder/base" | dirname -}}`
@@ -488,6 +578,106 @@ var _ = Context("filters", func() {
 			itShouldFailToRender(terraformCode, "thrown")
 		})
 	})
+	Context("get_path", func() {
+		BeforeEach(func() {
+			*template = heredoc.Doc(`
+				{%- set dictionary = {"spec": {"containers": [{"image": "nginx"}], "a/b": "escaped"}} -%}
+				{{- dictionary | get_path("spec.containers[0].image") -}}
+				{{- dictionary | get_path("/spec/a~1b", default="") -}}
+				{{- dictionary | get_path("spec.containers[1].image", default=" default") -}}
+			`)
+		})
+		itShouldSetTheExpectedResult(terraformCode, "nginxescaped default")
+		Context("when the path is missing in strict mode", func() {
+			BeforeEach(func() {
+				*template = `{{- {"spec": {}} | get_path("spec.replicas", strict=True) -}}`
+			})
+			itShouldFailToRender(terraformCode, "path 'spec.replicas' not found in: {'spec': {}}")
+		})
+		Context("when the path traverses a scalar", func() {
+			BeforeEach(func() {
+				*template = `{{- {"spec": {"replicas": 1}} | get_path("spec.replicas.count") -}}`
+			})
+			itShouldFailToRender(terraformCode, "can not traverse spec.replicas.count: spec.replicas holds the scalar 1 instead of a dict")
+		})
+		Context("when the path is invalid", func() {
+			BeforeEach(func() {
+				*template = `{{- {} | get_path("spec..replicas") -}}`
+			})
+			itShouldFailToRender(terraformCode, "path spec..replicas holds an empty key")
+		})
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- "thrown" | fail | get_path("spec") -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
+	Context("set_path", func() {
+		BeforeEach(func() {
+			*template = heredoc.Doc(`
+				{%- set dictionary = {"spec": {"replicas": 1}} -%}
+				{{- dictionary | set_path("spec.containers[0].image", "nginx") | set_path("/spec/containers/-", {"image": "envoy"}) -}}
+				{{- " " ~ dictionary -}}
+			`)
+		})
+		itShouldSetTheExpectedResult(terraformCode, "{'spec': {'containers': [{'image': 'nginx'}, {'image': 'envoy'}], 'replicas': 1}} {'spec': {'replicas': 1}}")
+		Context("when the path traverses a scalar", func() {
+			BeforeEach(func() {
+				*template = `{{- {"spec": {"replicas": 1}} | set_path("/spec/replicas/count", 2) -}}`
+			})
+			itShouldFailToRender(terraformCode, "can not traverse /spec/replicas/count: /spec/replicas holds the scalar 1 instead of a dict")
+		})
+		Context("when the index is out of range", func() {
+			BeforeEach(func() {
+				*template = `{{- {"list": []} | set_path("list[1]", 2) -}}`
+			})
+			itShouldFailToRender(terraformCode, "index 1 of list is out of range for a list of length 0")
+		})
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- "thrown" | fail | set_path("spec", 1) -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
+	Context("delete_path", func() {
+		BeforeEach(func() {
+			*template = heredoc.Doc(`
+				{%- set dictionary = {"spec": {"replicas": 1, "containers": [{"image": "nginx"}, {"image": "envoy"}]}} -%}
+				{{- dictionary | delete_path("spec.containers[0]") | delete_path("/spec/replicas") | delete_path("spec.missing") -}}
+			`)
+		})
+		itShouldSetTheExpectedResult(terraformCode, "{'spec': {'containers': [{'image': 'envoy'}]}}")
+		Context("when the path traverses a scalar", func() {
+			BeforeEach(func() {
+				*template = `{{- {"spec": "text"} | delete_path("spec.replicas") -}}`
+			})
+			itShouldFailToRender(terraformCode, "can not traverse spec.replicas: spec holds the scalar text instead of a dict")
+		})
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- "thrown" | fail | delete_path("spec") -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
+	Context("has_path", func() {
+		BeforeEach(func() {
+			*template = heredoc.Doc(`
+				{%- set dictionary = {"spec": {"containers": [{"image": "nginx"}]}} -%}
+				{{- dictionary | has_path("spec.containers[0].image") -}}
+				{{- " " ~ (dictionary | has_path("/spec/containers/1")) -}}
+			`)
+		})
+		itShouldSetTheExpectedResult(terraformCode, "True False")
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- "thrown" | fail | has_path("spec") -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
 	Context("ifelse", func() {
 		BeforeEach(func() {
 			*template = `{{- (name in "foo bar") | ifelse("name is in 'foo bar'", "name is not in 'foo bar'") -}}`
@@ -535,6 +725,59 @@ var _ = Context("filters", func() {
 			itShouldFailToRender(terraformCode, "thrown")
 		})
 	})
+	Context("ip_version", func() {
+		BeforeEach(func() {
+			*template = `{{- "10.0.0.1" | ip_version }} {{ "fd00::/8" | ip_version -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, "4 6")
+		Context("when the input is not an IP address", func() {
+			BeforeEach(func() {
+				*template = `{{- "foo" | ip_version -}}`
+			})
+			itShouldFailToRender(terraformCode, "foo is neither a valid IP address nor a valid CIDR notation")
+		})
+	})
+	Context("ip_in_range", func() {
+		BeforeEach(func() {
+			*template = `{{- "192.168.1.50" | ip_in_range("192.168.1.10", "192.168.1.100") }} {{ "192.168.1.5" | ip_in_range("192.168.1.10", "192.168.1.100") -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, "True False")
+		Context("when the bounds are not of the same version", func() {
+			BeforeEach(func() {
+				*template = `{{- "10.0.0.1" | ip_in_range("10.0.0.0", "fd00::1") -}}`
+			})
+			itShouldFailToRender(terraformCode, "10.0.0.0 and fd00::1 are not of the same IP version")
+		})
+	})
+	Context("ipaddr", func() {
+		BeforeEach(func() {
+			*template = heredoc.Doc(`
+				{%- set network = "192.168.1.10/24" -%}
+				{{- network | ipaddr("address") }} {{ network | ipaddr("network") }} {{ network | ipaddr("broadcast") }} {{ network | ipaddr("netmask") -}}
+				{{- " " }}{{ network | ipaddr("prefix") }} {{ network | ipaddr("size") }} {{ network | ipaddr("first_usable") }} {{ network | ipaddr("last_usable") -}}
+				{{- " " }}{{ network | ipaddr("private") }} {{ "foo" | ipaddr -}}
+			`)
+		})
+		itShouldSetTheExpectedResult(terraformCode, "192.168.1.10 192.168.1.0 192.168.1.255 255.255.255.0 24 256 192.168.1.1 192.168.1.254 True False")
+		Context("when the query is not supported", func() {
+			BeforeEach(func() {
+				*template = `{{- "10.0.0.1" | ipaddr("foo") -}}`
+			})
+			itShouldFailToRender(terraformCode, "unsupported query foo")
+		})
+		Context("when the size of an IPv6 network does not fit in an integer", func() {
+			BeforeEach(func() {
+				*template = `{{- "2001:db8::/32" | ipaddr("size") }} {{ "2001:db8::/96" | ipaddr("size") -}}`
+			})
+			itShouldSetTheExpectedResult(terraformCode, "79228162514264337593543950336 4294967296")
+		})
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- "thrown" | fail | ipaddr -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
 	Context("keys", func() {
 		BeforeEach(func() {
 			*template = `{{- {"a": "hey", "b": "bee", "c": "see"} | keys -}}`
@@ -586,450 +829,1768 @@ var _ = Context("filters", func() {
 			itShouldFailToRender(terraformCode, "thrown")
 		})
 	})
-	Context("split", func() {
+	Context("regex_replace", func() {
 		BeforeEach(func() {
 			*template = heredoc.Doc(`
-				{{- "root/folder/file" | split("/") }}
-				{{ "c,s,v" | split(",") }}
+				{{- "nginx:1.25.3" | regex_replace("^(?P<name>[^:]+):(\\d+)\\..*$", "\\g<name>-\\2") -}}
+				{{- " " ~ ("a-b-c" | regex_replace("-", "+", count=1)) -}}
+				{{- " " ~ ("Foo" | regex_replace("foo", "bar", ignorecase=True)) -}}
 			`)
 		})
-		itShouldSetTheExpectedResult(terraformCode, heredoc.Doc(`
-			['root', 'folder', 'file']
-			['c', 's', 'v']
-			
-		`))
-		Context("when the input is not a string", func() {
+		itShouldSetTheExpectedResult(terraformCode, "nginx-1 a+b-c bar")
+		Context("when the pattern is not a valid regex", func() {
 			BeforeEach(func() {
-				*template = `{{- [] | split("does not matter") -}}`
+				*template = `{{- "foo" | regex_replace("{.*[", "") -}}`
 			})
-			itShouldFailToRender(terraformCode, "\\[\\] is not a string")
+			itShouldFailToRender(terraformCode, "failed to compile: {.*\\[: error parsing regexp")
 		})
-		Context("when the argument is not a string", func() {
+		Context("when the input is not a string", func() {
 			BeforeEach(func() {
-				*template = `{{- "does not matter" | split(True) -}}`
+				*template = `{{- True | regex_replace("a", "b") -}}`
 			})
 			itShouldFailToRender(terraformCode, "True is not a string")
 		})
 		Context("when the input is an error", func() {
 			BeforeEach(func() {
-				*template = `{{- "thrown" | fail | split("does not matter") -}}`
+				*template = `{{- "thrown" | fail | regex_replace("a", "b") -}}`
 			})
 			itShouldFailToRender(terraformCode, "thrown")
 		})
 	})
-	Context("totoml", func() {
+	Context("regex_search", func() {
 		BeforeEach(func() {
 			*template = heredoc.Doc(`
-				{{- {} | totoml }}
-				{{ ['one'] | totoml }}
-				{{ True | totoml }}
-				{{ 'test' | totoml }}
-				{{ {"root": {"nested": { "foo": "bar" }}} | totoml }}
+				{{- "nginx:1.25.3" | regex_search(":(?P<major>\\d+)\\.(?P<minor>\\d+)") -}}
+				{{- " " ~ ("nginx:1.25.3" | regex_search(":(\\d+)\\.(\\d+)")) -}}
+				{{- " " ~ ("NGINX:1.25.3" | regex_search("^nginx", ignorecase=True)) -}}
 			`)
 		})
-		itShouldSetTheExpectedResult(terraformCode, heredoc.Doc(`
-
-			['one']
-			true
-			'test'
-			[root]
-			[root.nested]
-			foo = 'bar'
-			
-			
-		`))
-		Context("when the input is an error", func() {
+		itShouldSetTheExpectedResult(terraformCode, "{'major': '1', 'minor': '25'} ['1', '25'] NGINX")
+		Context("when the pattern does not match", func() {
 			BeforeEach(func() {
-				*template = `{{- "thrown" | fail | totoml -}}`
+				*template = `{{- "nginx" | regex_search("\\d+") is none -}}`
 			})
-			itShouldFailToRender(terraformCode, "thrown")
+			itShouldSetTheExpectedResult(terraformCode, "True")
 		})
-	})
-	Context("toyaml", func() {
-		BeforeEach(func() {
-			*template = heredoc.Doc(`
-				{{- {} | toyaml }}
-				{{ {"simple": {"nested": "field"}} | toyaml }}
-				{{ ["array"] | toyaml }}
-				{{ "string" | toyaml }}
-				{{ 42 | toyaml }}
-				{{ {"indented": {4: "spaces"}} | toyaml(indent=4) }}
-				{{ {"indented": {4: "spaces"}} | toyaml(indent=4) }}
-			`)
+		Context("when an optional group does not match", func() {
+			BeforeEach(func() {
+				*template = `{{- "ab" | regex_search("(a)(x)?") }} {{ ("ab" | regex_search("(?P<a>a)(?P<x>x)?")).x is none -}}`
+			})
+			itShouldSetTheExpectedResult(terraformCode, "['a', ''] True")
 		})
-		itShouldSetTheExpectedResult(terraformCode, heredoc.Doc(`
-			{}
-
-			simple:
-			  nested: field
-
-			- array
-
-			string
-
-			42
-
-			indented:
-			    4: spaces
-
-			indented:
-			    4: spaces
-			
-			
-		`))
 		Context("when the input is an error", func() {
 			BeforeEach(func() {
-				*template = `{{- "thrown" | fail | toyaml -}}`
+				*template = `{{- "thrown" | fail | regex_search("a") -}}`
 			})
 			itShouldFailToRender(terraformCode, "thrown")
 		})
 	})
-	Context("try", func() {
-		BeforeEach(func() {
-			*template = heredoc.Doc(`
-				{%- if (fail | try) is undefined -%}
-				Now you see me without errors!
-				{%- endif -%}
-				{%- if (no | try) -%}
-				But here you don't!
-				{%- endif -%}
-			`)
-		})
-		itShouldSetTheExpectedResult(terraformCode, "Now you see me without errors!")
-	})
-	Context("unset", func() {
+	Context("regex_findall", func() {
 		BeforeEach(func() {
 			*template = heredoc.Doc(`
-				{{- {"key": "will disappear", "still": "there"} | unset("key") }}
-				{{ {} | unset("nope") }}
+				{{- "a=1\nb=2" | regex_findall("^(\\w)=(\\d)$", multiline=True) -}}
 			`)
 		})
-		itShouldSetTheExpectedResult(terraformCode, heredoc.Doc(`
-			{'still': 'there'}
-			{}
-
-		`))
-		Context("when the input is not a dict", func() {
+		itShouldSetTheExpectedResult(terraformCode, "[['a', '1'], ['b', '2']]")
+		Context("when an optional group does not match", func() {
 			BeforeEach(func() {
-				*template = `{{- [] | unset("does not matter") -}}`
+				*template = `{{- "ab ax" | regex_findall("(a)(x)?") -}}`
 			})
-			itShouldFailToRender(terraformCode, "\\[\\] is not a dict")
+			itShouldSetTheExpectedResult(terraformCode, "[['a', ''], ['a', 'x']]")
 		})
-		Context("when the argument is not a string", func() {
+		Context("when the input is not a string", func() {
 			BeforeEach(func() {
-				*template = `{{- {} | unset(True) -}}`
+				*template = `{{- [] | regex_findall("a") -}}`
 			})
-			itShouldFailToRender(terraformCode, "True is not a string")
+			itShouldFailToRender(terraformCode, "\\[\\] is not a string")
+		})
+	})
+	Context("regex_split", func() {
+		BeforeEach(func() {
+			*template = heredoc.Doc(`
+				{{- "a, b;c" | regex_split("[,;]\\s*") -}}
+				{{- " " ~ ("a,b,c" | regex_split(",", maxsplit=1)) -}}
+			`)
 		})
+		itShouldSetTheExpectedResult(terraformCode, "['a', 'b', 'c'] ['a', 'b,c']")
 		Context("when the input is an error", func() {
 			BeforeEach(func() {
-				*template = `{{- "thrown" | fail | unset -}}`
+				*template = `{{- "thrown" | fail | regex_split(",") -}}`
 			})
 			itShouldFailToRender(terraformCode, "thrown")
 		})
 	})
-	Context("values", func() {
+	Context("regex_escape", func() {
 		BeforeEach(func() {
-			*template = `{{- {"one": 1, "two": 2, "three": 3} | values -}}`
+			*template = `{{- "example.com/a+b" | regex_escape -}}`
 		})
-		itShouldSetTheExpectedResult(terraformCode, "[1, 2, 3]")
-		Context("when the input is not a dict", func() {
+		itShouldSetTheExpectedResult(terraformCode, `example\.com/a\+b`)
+		Context("when the input is not a string", func() {
 			BeforeEach(func() {
-				*template = `{{- [] | values -}}`
+				*template = `{{- True | regex_escape -}}`
 			})
-			itShouldFailToRender(terraformCode, "\\[\\] is not a dict")
+			itShouldFailToRender(terraformCode, "True is not a string")
 		})
-		Context("when the input is an error", func() {
+	})
+	Context("quote_shell", func() {
+		BeforeEach(func() {
+			*template = `{{- "it's $(reboot); echo \"pwned\"" | quote_shell }} {{ "v1.2.3" | quote_shell }} {{ "" | quote_shell -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, `'it'"'"'s $(reboot); echo "pwned"' v1.2.3 ''`)
+		Context("when the input is not a string", func() {
 			BeforeEach(func() {
-				*template = `{{- "thrown" | fail | values -}}`
+				*template = `{{- 22 | quote_shell -}}`
 			})
-			itShouldFailToRender(terraformCode, "thrown")
+			itShouldFailToRender(terraformCode, "22 is not a string")
 		})
 	})
-	Context("abspath", Ordered, func() {
-		BeforeAll(func() {
-			*directory = os.TempDir()
+	Context("quote_hcl", func() {
+		BeforeEach(func() {
+			*template = `{{- "$${file(\"/etc/passwd\")} %%{ for x in y } $x" | quote_hcl -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, `"$${file(\"/etc/passwd\")} %%{ for x in y } $x"`)
+	})
+	Context("quote_json", func() {
+		BeforeEach(func() {
+			*template = `{{- "</script><script>alert(1)</script>\"" | quote_json -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, `"\u003c/script\u003e\u003cscript\u003ealert(1)\u003c/script\u003e\""`)
+	})
+	Context("quote_yaml", func() {
+		BeforeEach(func() {
+			*template = heredoc.Doc(`
+				{{- "yes" | quote_yaml }}
+				{{ "0755" | quote_yaml }}
+				{{ "key: value # comment" | quote_yaml }}
+				{{ "it's" | quote_yaml }}
+				{{ "line\nbreak" | quote_yaml }}
+				{{ "plain text" | quote_yaml }}
+				{{ "a?" | quote_yaml }}
+				{{ "0?" | quote_yaml }}
+				{{ "+?2" | quote_yaml }}
+			`)
+		})
+		itShouldSetTheExpectedResult(terraformCode, heredoc.Doc(`
+			'yes'
+			'0755'
+			'key: value # comment'
+			it's
+			"line\nbreak"
+			plain text
+			'a?'
+			'0?'
+			'+?2'
+		`))
+	})
+	Context("quote_regex", func() {
+		BeforeEach(func() {
+			*template = `{{- "1.2.3+build (rc)" | quote_regex -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, `1\.2\.3\+build \(rc\)`)
+	})
+	Context("quote_sql_ident", func() {
+		BeforeEach(func() {
+			*template = `{{- 'users"; DROP TABLE users; --' | quote_sql_ident }} {{ "a` + "`" + `b" | quote_sql_ident(dialect="mysql") -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, `"users""; DROP TABLE users; --" `+"`a``b`")
+		Context("when the input is empty", func() {
+			BeforeEach(func() {
+				*template = `{{- "" | quote_sql_ident -}}`
+			})
+			itShouldFailToRender(terraformCode, "identifiers can not be empty")
+		})
+		Context("when the dialect is not supported", func() {
+			BeforeEach(func() {
+				*template = `{{- "users" | quote_sql_ident(dialect="oracle") -}}`
+			})
+			itShouldFailToRender(terraformCode, "unsupported dialect oracle, expected one of ansi, mysql")
+		})
+	})
+	Context("quote_powershell", func() {
+		BeforeEach(func() {
+			*template = `{{- "it's $env:SECRET; ’; Remove-Item" | quote_powershell -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, `'it''s $env:SECRET; ’’; Remove-Item'`)
+	})
+	Context("snake_case, camel_case, pascal_case, kebab_case and constant_case", func() {
+		BeforeEach(func() {
+			*template = heredoc.Doc(`
+				{{- "HTTPServer2Name" | snake_case }}
+				{{ "HTTPServer2Name" | camel_case }}
+				{{ "db-instance class" | pascal_case }}
+				{{ "replicaCount" | kebab_case }}
+				{{ "replicaCount" | constant_case }}
+			`)
+		})
+		itShouldSetTheExpectedResult(terraformCode, heredoc.Doc(`
+			http_server2_name
+			httpServer2Name
+			DbInstanceClass
+			replica-count
+			REPLICA_COUNT
+		`))
+		Context("when the input is not a string", func() {
+			BeforeEach(func() {
+				*template = `{{- 1 | snake_case -}}`
+			})
+			itShouldFailToRender(terraformCode, "1 is not a string")
+		})
+	})
+	Context("slugify", func() {
+		BeforeEach(func() {
+			*template = `{{- "Crème Brûlée & Straße" | slugify }} {{ "Hello World" | slugify(separator="_") }} {{ "a very long title" | slugify(max_length=7) -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, "creme-brulee-strasse hello_world a-very")
+	})
+	Context("dns_label", func() {
+		BeforeEach(func() {
+			*template = `{{- "Payments_API.v2" | dns_label }} {{ "my-very-long-release-name-for-the-payments-api" | dns_label(max_length=24) -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, "payments-api-v2 my-very-long-re-79027f2d")
+		Context("when the input holds no allowed character", func() {
+			BeforeEach(func() {
+				*template = `{{- "!!!" | dns_label -}}`
+			})
+			itShouldFailToRender(terraformCode, "!!! holds no character allowed in a DNS label")
+		})
+		Context("when the maximum length is too large", func() {
+			BeforeEach(func() {
+				*template = `{{- "name" | dns_label(max_length=64) -}}`
+			})
+			itShouldFailToRender(terraformCode, "max_length must be between 10 and 63")
+		})
+	})
+	Context("k8s_name", func() {
+		BeforeEach(func() {
+			*template = `{{- "Payments_API.v2" | k8s_name }} {{ "..a..B.." | k8s_name -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, "payments-api.v2 a.b")
+	})
+	Context("split", func() {
+		BeforeEach(func() {
+			*template = heredoc.Doc(`
+				{{- "root/folder/file" | split("/") }}
+				{{ "c,s,v" | split(",") }}
+			`)
+		})
+		itShouldSetTheExpectedResult(terraformCode, heredoc.Doc(`
+			['root', 'folder', 'file']
+			['c', 's', 'v']
+			
+		`))
+		Context("when the input is not a string", func() {
+			BeforeEach(func() {
+				*template = `{{- [] | split("does not matter") -}}`
+			})
+			itShouldFailToRender(terraformCode, "\\[\\] is not a string")
+		})
+		Context("when the argument is not a string", func() {
+			BeforeEach(func() {
+				*template = `{{- "does not matter" | split(True) -}}`
+			})
+			itShouldFailToRender(terraformCode, "True is not a string")
+		})
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- "thrown" | fail | split("does not matter") -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
+	Context("strftime", func() {
+		BeforeEach(func() {
+			*template = `{{- "2024-03-05T14:07:09Z" | strftime("%A %d %B %Y at %H:%M") }} {{ 0 | strftime("%Y-%m-%dT%H:%M:%S%z") -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, "Tuesday 05 March 2024 at 14:07 1970-01-01T00:00:00+0000")
+		Context("when the format holds an unsupported directive", func() {
+			BeforeEach(func() {
+				*template = `{{- 0 | strftime("%Q") -}}`
+			})
+			itShouldFailToRender(terraformCode, "unsupported directive %Q in format %Q")
+		})
+		Context("when the input is not a valid timestamp", func() {
+			BeforeEach(func() {
+				*template = `{{- "2024-03-05" | strftime("%Y") -}}`
+			})
+			itShouldFailToRender(terraformCode, "filter 'strftime': 2024-03-05 is not a valid RFC 3339 timestamp")
+		})
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- "thrown" | fail | strftime("%Y") -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
+	Context("todatetime", func() {
+		BeforeEach(func() {
+			*template = `{{- "05/03/2024 2:07 PM" | todatetime(format="%d/%m/%Y %I:%M %p") }} {{ 1709647629 | todatetime -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, "2024-03-05T14:07:00Z 2024-03-05T14:07:09Z")
+		Context("when the input does not match the format", func() {
+			BeforeEach(func() {
+				*template = `{{- "2024/03/05" | todatetime(format="%Y-%m-%d") -}}`
+			})
+			itShouldFailToRender(terraformCode, "filter 'todatetime': 2024/03/05 does not match format %Y-%m-%d")
+		})
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- "thrown" | fail | todatetime -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
+	Context("toepoch", func() {
+		BeforeEach(func() {
+			*template = `{{- "2024-03-05T14:07:09Z" | toepoch -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, "1709647629")
+		Context("when the input is neither a string nor a number", func() {
+			BeforeEach(func() {
+				*template = `{{- True | toepoch -}}`
+			})
+			itShouldFailToRender(terraformCode, "True is neither a string nor a number")
+		})
+	})
+	Context("timeadd", func() {
+		BeforeEach(func() {
+			*template = `{{- "2024-03-05T14:07:09Z" | timeadd("1h30m") }} {{ "2024-03-05T14:07:09+02:00" | timeadd("-10m") -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, "2024-03-05T15:37:09Z 2024-03-05T13:57:09+02:00")
+		Context("when the duration is invalid", func() {
+			BeforeEach(func() {
+				*template = `{{- "2024-03-05T14:07:09Z" | timeadd("1d") -}}`
+			})
+			itShouldFailToRender(terraformCode, "filter 'timeadd': 1d is not a valid duration")
+		})
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- "thrown" | fail | timeadd("1h") -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
+	Context("timecmp", func() {
+		BeforeEach(func() {
+			*template = `{{- "2024-03-05T14:07:09Z" | timecmp("2024-03-05T15:07:09+01:00") }} {{ "2024-03-05T14:07:09Z" | timecmp("2025-01-01T00:00:00Z") }} {{ "2024-03-05T14:07:09Z" | timecmp(0) -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, "0 -1 1")
+		Context("when the argument is not a valid timestamp", func() {
+			BeforeEach(func() {
+				*template = `{{- "2024-03-05T14:07:09Z" | timecmp("tomorrow") -}}`
+			})
+			itShouldFailToRender(terraformCode, "filter 'timecmp': tomorrow is not a valid RFC 3339 timestamp")
+		})
+	})
+	Context("toduration", func() {
+		BeforeEach(func() {
+			*template = `{{- "1h30m" | toduration }} {{ "1.5s" | toduration -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, "5400 1.5")
+		Context("when the input is not a valid duration", func() {
+			BeforeEach(func() {
+				*template = `{{- "1 day" | toduration -}}`
+			})
+			itShouldFailToRender(terraformCode, "filter 'toduration': 1 day is not a valid duration")
+		})
+		Context("when the input is not a string", func() {
+			BeforeEach(func() {
+				*template = `{{- 60 | toduration -}}`
+			})
+			itShouldFailToRender(terraformCode, "60 is not a string")
+		})
+	})
+	Context("totoml", func() {
+		BeforeEach(func() {
+			*template = heredoc.Doc(`
+				{{- {} | totoml }}
+				{{ ['one'] | totoml }}
+				{{ True | totoml }}
+				{{ 'test' | totoml }}
+				{{ {"root": {"nested": { "foo": "bar" }}} | totoml }}
+			`)
+		})
+		itShouldSetTheExpectedResult(terraformCode, heredoc.Doc(`
 
-			Must(os.MkdirAll(path.Join(*directory, "abspath"), 0700))
+			['one']
+			true
+			'test'
+			[root]
+			[root.nested]
+			foo = 'bar'
+			
+			
+		`))
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- "thrown" | fail | totoml -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
+	Context("toyaml", func() {
+		BeforeEach(func() {
+			*template = heredoc.Doc(`
+				{{- {} | toyaml }}
+				{{ {"simple": {"nested": "field"}} | toyaml }}
+				{{ ["array"] | toyaml }}
+				{{ "string" | toyaml }}
+				{{ 42 | toyaml }}
+				{{ {"indented": {4: "spaces"}} | toyaml(indent=4) }}
+				{{ {"indented": {4: "spaces"}} | toyaml(indent=4) }}
+			`)
+		})
+		itShouldSetTheExpectedResult(terraformCode, heredoc.Doc(`
+			{}
 
-			MustReturn(os.Create(path.Join(*directory, "abspath", "file.txt"))).Close()
+			simple:
+			  nested: field
 
-			*template = `{{- "./abspath/file.txt" | abspath -}}`
+			- array
+
+			string
+
+			42
+
+			indented:
+			    4: spaces
+
+			indented:
+			    4: spaces
+			
+			
+		`))
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- "thrown" | fail | toyaml -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
+	Context("try", func() {
+		BeforeEach(func() {
+			*template = heredoc.Doc(`
+				{%- if (fail | try) is undefined -%}
+				Now you see me without errors!
+				{%- endif -%}
+				{%- if (no | try) -%}
+				But here you don't!
+				{%- endif -%}
+			`)
+		})
+		itShouldSetTheExpectedResult(terraformCode, "Now you see me without errors!")
+	})
+	Context("unset", func() {
+		BeforeEach(func() {
+			*template = heredoc.Doc(`
+				{{- {"key": "will disappear", "still": "there"} | unset("key") }}
+				{{ {} | unset("nope") }}
+			`)
+		})
+		itShouldSetTheExpectedResult(terraformCode, heredoc.Doc(`
+			{'still': 'there'}
+			{}
+
+		`))
+		Context("when the input is not a dict", func() {
+			BeforeEach(func() {
+				*template = `{{- [] | unset("does not matter") -}}`
+			})
+			itShouldFailToRender(terraformCode, "\\[\\] is not a dict")
+		})
+		Context("when the argument is not a string", func() {
+			BeforeEach(func() {
+				*template = `{{- {} | unset(True) -}}`
+			})
+			itShouldFailToRender(terraformCode, "True is not a string")
+		})
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- "thrown" | fail | unset -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
+	Context("values", func() {
+		BeforeEach(func() {
+			*template = `{{- {"one": 1, "two": 2, "three": 3} | values -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, "[1, 2, 3]")
+		Context("when the input is not a dict", func() {
+			BeforeEach(func() {
+				*template = `{{- [] | values -}}`
+			})
+			itShouldFailToRender(terraformCode, "\\[\\] is not a dict")
+		})
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- "thrown" | fail | values -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
+	Context("version_compare", func() {
+		BeforeEach(func() {
+			*template = `{{- "1.9.0" | version_compare("1.25", "<") }} {{ 1.25 | version_compare("1.25.0", "eq") }} {{ "1.2.0-rc.1" | version_compare("1.2.0", ">=") -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, "True True False")
+		Context("when the operator is not supported", func() {
+			BeforeEach(func() {
+				*template = `{{- "1.9.0" | version_compare("1.25", "~>") -}}`
+			})
+			itShouldFailToRender(terraformCode, "unsupported operator ~>")
+		})
+		Context("when the input is not a valid version", func() {
+			BeforeEach(func() {
+				*template = `{{- "latest" | version_compare("1.25", "<") -}}`
+			})
+			itShouldFailToRender(terraformCode, "latest is not a valid version")
+		})
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- "thrown" | fail | version_compare("1.25", "<") -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
+	Context("version_sort", func() {
+		BeforeEach(func() {
+			*template = `{{- ["1.10.0", "v1.9", "1.2.0", "1.2.0-beta"] | version_sort }} {{ ["1.10.0", "1.9.0"] | version_sort(reverse=True) -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, "['1.2.0-beta', '1.2.0', 'v1.9', '1.10.0'] ['1.10.0', '1.9.0']")
+		Context("when the input is not a list", func() {
+			BeforeEach(func() {
+				*template = `{{- "1.9.0" | version_sort -}}`
+			})
+			itShouldFailToRender(terraformCode, "1.9.0 is not a list")
+		})
+		Context("when an item is not a valid version", func() {
+			BeforeEach(func() {
+				*template = `{{- ["1.9.0", "latest"] | version_sort -}}`
+			})
+			itShouldFailToRender(terraformCode, "latest is not a valid version")
+		})
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- "thrown" | fail | version_sort -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
+	Context("version_parts", func() {
+		BeforeEach(func() {
+			*template = `{%- set parts = "1.25.3-rc.1+build.5" | version_parts -%}{{ parts.major }} {{ parts.minor }} {{ parts.patch }} {{ parts.prerelease }} {{ parts.metadata }}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, "1 25 3 rc.1 build.5")
+		Context("when the input is neither a string nor a number", func() {
+			BeforeEach(func() {
+				*template = `{{- [1] | version_parts -}}`
+			})
+			itShouldFailToRender(terraformCode, "\\[1\\] is neither a string nor a number")
+		})
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- "thrown" | fail | version_parts -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
+	Context("abspath", Ordered, func() {
+		BeforeAll(func() {
+			*directory = os.TempDir()
+
+			Must(os.MkdirAll(path.Join(*directory, "abspath"), 0700))
+
+			MustReturn(os.Create(path.Join(*directory, "abspath", "file.txt"))).Close()
+
+			*template = `{{- "./abspath/file.txt" | abspath -}}`
+		})
+		AfterAll(func() {
+			os.RemoveAll(*directory)
+		})
+		itShouldSetTheExpectedResult(terraformCode, path.Join(os.TempDir(), "abspath", "file.txt"))
+		Context("when the input is not a string", func() {
+			BeforeEach(func() {
+				*template = `{{- true | abspath -}}`
+			})
+			itShouldFailToRender(terraformCode, "True is not a string")
+		})
+	})
+	Context("distinct", func() {
+		BeforeEach(func() {
+			*template = `{{- ["a", "b", "a", "c", "d", "b"] | distinct -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, "['a', 'b', 'c', 'd']")
+		Context("when the input is a list of integers", func() {
+			BeforeEach(func() {
+				*template = `{{- [1,1,1,2,3,3,2,3] | distinct -}}`
+			})
+			itShouldSetTheExpectedResult(terraformCode, "[1, 2, 3]")
+		})
+		Context("when the input is a list of objects", func() {
+			BeforeEach(func() {
+				*template = `{{- [{"one": 1}, {"two": 2}, {"one": 1}] | distinct -}}`
+			})
+			itShouldSetTheExpectedResult(terraformCode, "[{'one': 1}, {'two': 2}]")
+		})
+		Context("when the input is a list of lists", func() {
+			BeforeEach(func() {
+				*template = `{{- [[1,2], [1,"two"], [1,"two"]] | distinct -}}`
+			})
+			itShouldSetTheExpectedResult(terraformCode, "[[1, 2], [1, 'two']]")
+		})
+		Context("when the input is not a list", func() {
+			BeforeEach(func() {
+				*template = `{{- {} | distinct -}}`
+			})
+			itShouldFailToRender(terraformCode, "{} is not a list")
+		})
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- "thrown" | fail | distinct -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
+	Context("union, intersect, difference and symmetric_difference", func() {
+		BeforeEach(func() {
+			*template = heredoc.Doc(`
+				{{- [3, 1, 2, 1] | union([2, 4, 3]) }}
+				{{ [1, 2, 3, 2] | intersect([3, 2, 9]) }}
+				{{ [1, 2, 3, 1] | difference([2]) }}
+				{{ [1, 2, 3] | symmetric_difference([3, 4, 4]) }}
+				{{ [{"a": [1]}, {"a": [2]}] | difference([{"a": [1]}]) }}
+			`)
+		})
+		itShouldSetTheExpectedResult(terraformCode, heredoc.Doc(`
+			[3, 1, 2, 4]
+			[2, 3]
+			[1, 3]
+			[1, 2, 4]
+			[{'a': [2]}]
+		`))
+		Context("when comparing on an attribute", func() {
+			BeforeEach(func() {
+				*template = heredoc.Doc(`
+					{%- set current = [{"id": "sg-1", "port": 22}, {"id": "sg-2", "port": 443}] -%}
+					{%- set desired = [{"id": "sg-2", "port": 8443}, {"id": "sg-3", "port": 80}] -%}
+					{{ desired | difference(current, by="id") }}
+					{{ current | intersect(desired, by="id") }}
+					{{ [{"meta": {"name": "a"}}] | union([{"meta": {"name": "a"}, "other": 1}], by="meta.name") }}
+				`)
+			})
+			itShouldSetTheExpectedResult(terraformCode, heredoc.Doc(`
+				[{'id': 'sg-3', 'port': 80}]
+				[{'id': 'sg-2', 'port': 443}]
+				[{'meta': {'name': 'a'}}]
+			`))
+		})
+		Context("when a list holds None", func() {
+			BeforeEach(func() {
+				*template = `{{- [None, 1] | union([2]) }} {{ ([None, 1] | union([2]))[0] is none }} {{ [None, 1] | difference([1]) | length -}}`
+			})
+			itShouldSetTheExpectedResult(terraformCode, "[, 1, 2] True 1")
+		})
+		Context("when an element has no such attribute", func() {
+			BeforeEach(func() {
+				*template = `{{- [{"id": 1}, {}] | union([], by="id") -}}`
+			})
+			itShouldFailToRender(terraformCode, "{} has no attribute id")
+		})
+		Context("when the argument is not a list", func() {
+			BeforeEach(func() {
+				*template = `{{- [] | difference("b") -}}`
+			})
+			itShouldFailToRender(terraformCode, "b is not a list")
+		})
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- "thrown" | fail | intersect([]) -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
+	Context("env", func() {
+		BeforeEach(func() {
+			Must(os.Setenv("FOO", "BAR"))
+
+			*template = `{{- "FOO" | env -}}`
+		})
+		AfterEach(func() {
+			Must(os.Unsetenv("FOO"))
+		})
+		itShouldSetTheExpectedResult(terraformCode, "BAR")
+		Context("when the environment variable does not exist", func() {
+			BeforeEach(func() {
+				*template = `{{- "NOPE" | env -}}`
+			})
+			itShouldFailToRender(terraformCode, "failed to get 'NOPE' environment variable without default")
+			Context("but a default was defined", func() {
+				BeforeEach(func() {
+					*template = `{{- "NOPE" | env(default="BAR") -}}`
+				})
+				itShouldSetTheExpectedResult(terraformCode, "BAR")
+			})
+		})
+		Context("when the input is not a string", func() {
+			BeforeEach(func() {
+				*template = `{{- True | env -}}`
+			})
+			itShouldFailToRender(terraformCode, "True is not a string")
+		})
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- "thrown" | fail | env -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
+	Context("tobase64", func() {
+		BeforeEach(func() {
+			*template = `{{- "test" | tobase64 -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, "dGVzdA==")
+		Context("when using the URL safe alphabet without padding", func() {
+			BeforeEach(func() {
+				*template = `{{- "??>t" | tobase64(urlsafe=True, padding=False) -}}`
+			})
+			itShouldSetTheExpectedResult(terraformCode, "Pz8-dA")
+		})
+		Context("when the input is not a string", func() {
+			BeforeEach(func() {
+				*template = `{{- True | tobase64 -}}`
+			})
+			itShouldFailToRender(terraformCode, "True is not a string")
+		})
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- "thrown" | fail | tobase64 -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
+	Context("frombase64", func() {
+		BeforeEach(func() {
+			*template = `{{- "dGVzdA==" | frombase64 -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, "test")
+		Context("when using the URL safe alphabet without padding", func() {
+			BeforeEach(func() {
+				*template = `{{- "Pz8-dA" | frombase64(urlsafe=True) -}}`
+			})
+			itShouldSetTheExpectedResult(terraformCode, "??>t")
+		})
+		Context("when the input is not a string", func() {
+			BeforeEach(func() {
+				*template = `{{- True | frombase64 -}}`
+			})
+			itShouldFailToRender(terraformCode, "True is not a string")
+		})
+		Context("when the input is not a valid base64 encoding", func() {
+			BeforeEach(func() {
+				*template = `{{- "❌" | frombase64 -}}`
+			})
+			itShouldFailToRender(terraformCode, "failed to decode '❌' from base64")
+		})
+		Context("when the padding is incorrect", func() {
+			BeforeEach(func() {
+				*template = `{{- "YQ=====" | frombase64 -}}`
+			})
+			itShouldFailToRender(terraformCode, "failed to decode 'YQ=====' from base64")
+		})
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- "thrown" | fail | frombase64 -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
+	Context("tobase32", func() {
+		BeforeEach(func() {
+			*template = `{{- "foobar" | tobase32 -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, "MZXW6YTBOI======")
+		Context("when the input is not a string", func() {
+			BeforeEach(func() {
+				*template = `{{- True | tobase32 -}}`
+			})
+			itShouldFailToRender(terraformCode, "True is not a string")
+		})
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- "thrown" | fail | tobase32 -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
+	Context("frombase32", func() {
+		BeforeEach(func() {
+			*template = `{{- "MZXW6YTBOI======" | frombase32 }} {{ "MZXW6YTBOI" | frombase32 -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, "foobar foobar")
+		Context("when the input is not a valid base32 encoding", func() {
+			BeforeEach(func() {
+				*template = `{{- "1" | frombase32 -}}`
+			})
+			itShouldFailToRender(terraformCode, "failed to decode '1' from base32")
+		})
+		Context("when the padding is incorrect", func() {
+			BeforeEach(func() {
+				*template = `{{- "MZXW6YTBOI=" | frombase32 -}}`
+			})
+			itShouldFailToRender(terraformCode, "failed to decode 'MZXW6YTBOI=' from base32")
+		})
+		Context("when the input is not a string", func() {
+			BeforeEach(func() {
+				*template = `{{- True | frombase32 -}}`
+			})
+			itShouldFailToRender(terraformCode, "True is not a string")
+		})
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- "thrown" | fail | frombase32 -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
+	Context("tohex", func() {
+		BeforeEach(func() {
+			*template = `{{- "Hello" | tohex -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, "48656c6c6f")
+		Context("when the input is not a string", func() {
+			BeforeEach(func() {
+				*template = `{{- True | tohex -}}`
+			})
+			itShouldFailToRender(terraformCode, "True is not a string")
+		})
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- "thrown" | fail | tohex -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
+	Context("fromhex", func() {
+		BeforeEach(func() {
+			*template = `{{- "48656c6c6f" | fromhex -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, "Hello")
+		Context("when the input is not a valid hexadecimal encoding", func() {
+			BeforeEach(func() {
+				*template = `{{- "zz" | fromhex -}}`
+			})
+			itShouldFailToRender(terraformCode, "failed to decode 'zz' from hex")
+		})
+		Context("when the input is not a string", func() {
+			BeforeEach(func() {
+				*template = `{{- True | fromhex -}}`
+			})
+			itShouldFailToRender(terraformCode, "True is not a string")
+		})
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- "thrown" | fail | fromhex -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
+	Context("fromcsv", func() {
+		BeforeEach(func() {
+			*template = `{{- "a,b,c\n1,2,3\n4,5,6" | fromcsv -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, "[{'a': '1', 'b': '2', 'c': '3'}, {'a': '4', 'b': '5', 'c': '6'}]")
+		Context("when the input is not a string", func() {
+			BeforeEach(func() {
+				*template = `{{- True | fromcsv -}}`
+			})
+			itShouldFailToRender(terraformCode, "True is not a string")
+		})
+		Context("when the input is not a valid csv", func() {
+			BeforeEach(func() {
+				*template = `{{- "nope,;s\nssss" | fromcsv -}}`
+			})
+			itShouldFailToRender(terraformCode, "record on line 2: wrong number of fields")
+		})
+		Context("when using a custom delimiter with type inference", func() {
+			BeforeEach(func() {
+				*template = `{{- "a;b\n1;true\n2.5;x" | fromcsv(delimiter=";", infer_types=True) -}}`
+			})
+			itShouldSetTheExpectedResult(terraformCode, "[{'a': 1, 'b': True}, {'a': 2.5, 'b': 'x'}]")
+		})
+		Context("when the input has no header", func() {
+			BeforeEach(func() {
+				*template = `{{- "a,b\n1,2" | fromcsv(header=False) -}}`
+			})
+			itShouldSetTheExpectedResult(terraformCode, "[['a', 'b'], ['1', '2']]")
+		})
+		Context("when indexing rows by a column", func() {
+			BeforeEach(func() {
+				*template = `{{- "name,port\nweb,443\ndb,5432" | fromcsv(index_by="name") -}}`
+			})
+			itShouldSetTheExpectedResult(terraformCode, "{'db': {'name': 'db', 'port': '5432'}, 'web': {'name': 'web', 'port': '443'}}")
+		})
+		Context("when indexing rows by a duplicated column", func() {
+			BeforeEach(func() {
+				*template = `{{- "name\nweb\nweb" | fromcsv(index_by="name") -}}`
+			})
+			itShouldFailToRender(terraformCode, "2nd row has a duplicated name value: web")
+		})
+		Context("when the delimiter is not a single character", func() {
+			BeforeEach(func() {
+				*template = `{{- "a" | fromcsv(delimiter=";;") -}}`
+			})
+			itShouldFailToRender(terraformCode, `delimiter ";;" must be a single character`)
+		})
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- "thrown" | fail | fromcsv -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
+	Context("fromtfstate", func() {
+		BeforeEach(func() {
+			*template = heredoc.Doc(`
+				{%- set state = {
+					"format_version": "1.0",
+					"values": {
+						"outputs": {
+							"endpoint": {"sensitive": False, "value": "db.internal"},
+							"password": {"sensitive": True, "value": "hunter2"}
+						},
+						"root_module": {
+							"child_modules": [{
+								"address": "module.db",
+								"resources": [{
+									"address": "module.db.aws_instance.primary[0]",
+									"mode": "managed",
+									"type": "aws_instance",
+									"name": "primary",
+									"index": 0,
+									"provider_name": "registry.terraform.io/hashicorp/aws",
+									"values": {"port": 5432, "token": "secret"},
+									"sensitive_values": {"token": True}
+								}]
+							}]
+						}
+					}
+				} | tojson | fromtfstate -%}
+				{{ state.outputs | tojson }}
+				{{ state.modules | tojson }}
+				{{ state.resources | tojson }}
+			`)
+		})
+		itShouldSetTheExpectedResult(terraformCode, heredoc.Doc(`
+			{"endpoint":"db.internal","password":"(sensitive value)"}
+			["module.db"]
+			[{"address":"module.db.aws_instance.primary[0]","index":0,"mode":"managed","module":"module.db","name":"primary","provider":"registry.terraform.io/hashicorp/aws","type":"aws_instance","values":{"port":5432,"token":"(sensitive value)"}}]
+			
+		`))
+		Context("when allowing sensitive values", func() {
+			BeforeEach(func() {
+				*template = `{%- set state = {"format_version": "1.0", "values": {"outputs": {"password": {"sensitive": True, "value": "hunter2"}}}} | tojson | fromtfstate(allow_sensitive=True) -%}{{ state.outputs | tojson }}`
+			})
+			itShouldSetTheExpectedResult(terraformCode, `{"password":"hunter2"}`)
+		})
+		Context("when the input is a plan", func() {
+			BeforeEach(func() {
+				*template = heredoc.Doc(`
+					{%- set plan = {
+						"format_version": "1.2",
+						"planned_values": {
+							"root_module": {
+								"resources": [{"address": "aws_instance.web", "mode": "managed", "type": "aws_instance", "name": "web", "values": {"port": 443}}]
+							}
+						},
+						"resource_changes": [
+							{"address": "aws_instance.web", "mode": "managed", "type": "aws_instance", "name": "web", "change": {"actions": ["create"]}},
+							{"address": "aws_instance.old", "mode": "managed", "type": "aws_instance", "name": "old", "change": {"actions": ["delete"]}}
+						]
+					} | tojson | fromtfstate -%}
+					{{ plan.resources[0].actions | tojson }}
+					{% for change in plan.changes -%}
+					{{ change.address }}: {{ change.actions | join(",") }}
+					{% endfor -%}
+				`)
+			})
+			itShouldSetTheExpectedResult(terraformCode, heredoc.Doc(`
+				["create"]
+				aws_instance.web: create
+				aws_instance.old: delete
+			`))
+		})
+		Context("when the format version is missing", func() {
+			BeforeEach(func() {
+				*template = `{{- "{}" | fromtfstate -}}`
+			})
+			itShouldFailToRender(terraformCode, "failed to parse terraform state: invalid state: unexpected state input, format version is missing")
+		})
+		Context("when the input is not a string", func() {
+			BeforeEach(func() {
+				*template = `{{- True | fromtfstate -}}`
+			})
+			itShouldFailToRender(terraformCode, "True is not a string")
+		})
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- "thrown" | fail | fromtfstate -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
+	Context("fromtfvars", func() {
+		BeforeEach(func() {
+			*template = `{{- 'foo = "bar"\nobj = {\ntest = 123\n}' | fromtfvars -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, "{'foo': 'bar', 'obj': {'test': 123}}")
+		Context("when the input is not a string", func() {
+			BeforeEach(func() {
+				*template = `{{- True | fromtfvars -}}`
+			})
+			itShouldFailToRender(terraformCode, "True is not a string")
+		})
+		Context("when the input is not a valid tfvars file", func() {
+			BeforeEach(func() {
+				*template = `{{- "nope,;ssss" | fromtfvars -}}`
+			})
+			itShouldFailToRender(terraformCode, "failed to parse 'nope,;ssss' as tfvars")
+		})
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- "thrown" | fail | fromtfvars -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
+	Context("sha1", func() {
+		BeforeEach(func() {
+			*template = `{{- 'test' | sha1 -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, "a94a8fe5ccb19ba61c4c0873d391e987982fbbd3")
+		Context("when the input is not a string", func() {
+			BeforeEach(func() {
+				*template = `{{- True | sha1 -}}`
+			})
+			itShouldFailToRender(terraformCode, "True is not a string")
+		})
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- "thrown" | fail | sha1 -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
+	Context("sha256", func() {
+		BeforeEach(func() {
+			*template = `{{- 'test' | sha256 -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08")
+		Context("when the input is not a string", func() {
+			BeforeEach(func() {
+				*template = `{{- True | sha256 -}}`
+			})
+			itShouldFailToRender(terraformCode, "True is not a string")
+		})
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- "thrown" | fail | sha256 -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
+	Context("sha512", func() {
+		BeforeEach(func() {
+			*template = `{{- 'test' | sha512 -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, "ee26b0dd4af7e749aa1a8ee3c10ae9923f618980772e473f8819a5d4940e0db27ac185f8a0e1d5f84f88bc887fd67b143732c304cc5fa9ad8e6f57f50028a8ff")
+		Context("when the input is not a string", func() {
+			BeforeEach(func() {
+				*template = `{{- True | sha512 -}}`
+			})
+			itShouldFailToRender(terraformCode, "True is not a string")
+		})
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- "thrown" | fail | sha512 -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
+	Context("md5", func() {
+		BeforeEach(func() {
+			*template = `{{- 'test' | md5 -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, "098f6bcd4621d373cade4e832627b4f6")
+		Context("when the input is not a string", func() {
+			BeforeEach(func() {
+				*template = `{{- True | md5 -}}`
+			})
+			itShouldFailToRender(terraformCode, "invalid call to filter 'md5': True is not a string")
+		})
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- "thrown" | fail | md5 -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
+	Context("sha3_256", func() {
+		BeforeEach(func() {
+			*template = `{{- 'abc' | sha3_256 -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, "3a985da74fe225b2045c172d6bd390bd855f086e3e9d525b46bfe24511431532")
+		Context("when the input is not a string", func() {
+			BeforeEach(func() {
+				*template = `{{- True | sha3_256 -}}`
+			})
+			itShouldFailToRender(terraformCode, "True is not a string")
+		})
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- "thrown" | fail | sha3_256 -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
+	Context("sha3_512", func() {
+		BeforeEach(func() {
+			*template = `{{- 'abc' | sha3_512 -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, "b751850b1a57168a5693cd924b6b096e08f621827444f70d884f5d0240d2712e10e116e9192af3c91a7ec57647e3934057340b4cf408d5a56592f8274eec53f0")
+		Context("when the input is not a string", func() {
+			BeforeEach(func() {
+				*template = `{{- True | sha3_512 -}}`
+			})
+			itShouldFailToRender(terraformCode, "True is not a string")
+		})
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- "thrown" | fail | sha3_512 -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
+	Context("blake2b", func() {
+		BeforeEach(func() {
+			*template = `{{- "abc" | blake2b(digest_size=16) -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, "cf4ab791c62b8d2b2109c90275287816")
+		Context("when the digest size is out of range", func() {
+			BeforeEach(func() {
+				*template = `{{- "abc" | blake2b(digest_size=65) -}}`
+			})
+			itShouldFailToRender(terraformCode, "digest_size must be between 1 and 64")
+		})
+		Context("when the input is not a string", func() {
+			BeforeEach(func() {
+				*template = `{{- True | blake2b -}}`
+			})
+			itShouldFailToRender(terraformCode, "True is not a string")
+		})
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- "thrown" | fail | blake2b -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
+	Context("hmac", func() {
+		BeforeEach(func() {
+			*template = `{{- "message" | hmac("key") }} {{ "message" | hmac("key", "sha1") -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, "6e9ef29b75fffc5b7abae527d58fdadb2fe42e7219011976917343065f58ed4a 2088df74d5f2146b48146caf4965377e9d0be3a4")
+		Context("when the algorithm is not supported", func() {
+			BeforeEach(func() {
+				*template = `{{- "message" | hmac("key", "sha999") -}}`
+			})
+			itShouldFailToRender(terraformCode, "unsupported algorithm sha999")
+		})
+		Context("when the input is not a string", func() {
+			BeforeEach(func() {
+				*template = `{{- True | hmac("key") -}}`
+			})
+			itShouldFailToRender(terraformCode, "True is not a string")
+		})
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- "thrown" | fail | hmac("key") -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
+	Context("crc32", func() {
+		BeforeEach(func() {
+			*template = `{{- "The quick brown fox jumps over the lazy dog" | crc32 -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, "414fa339")
+		Context("when the input is not a string", func() {
+			BeforeEach(func() {
+				*template = `{{- True | crc32 -}}`
+			})
+			itShouldFailToRender(terraformCode, "True is not a string")
+		})
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- "thrown" | fail | crc32 -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
+	Context("bcrypt", func() {
+		BeforeEach(func() {
+			*template = `{{- "password" | bcrypt(cost=4, salt="abcdefghijklmnopqrstuu") -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, "$2a$04$abcdefghijklmnopqrstuughE8Ev8uGFaUgY2cNEySvxngrb/Jzdm")
+		Context("when the salt is invalid", func() {
+			BeforeEach(func() {
+				*template = `{{- "password" | bcrypt(salt="short") -}}`
+			})
+			itShouldFailToRender(terraformCode, "salt short is not made of 22 characters")
+		})
+		Context("when the input is not a string", func() {
+			BeforeEach(func() {
+				*template = `{{- True | bcrypt -}}`
+			})
+			itShouldFailToRender(terraformCode, "True is not a string")
+		})
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- "thrown" | fail | bcrypt -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
+	Context("argon2id", func() {
+		BeforeEach(func() {
+			*template = `{{- "password" | argon2id(salt="somesalt", time=2, threads=1) -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, "$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc")
+		Context("when the salt is too short", func() {
+			BeforeEach(func() {
+				*template = `{{- "password" | argon2id(salt="short") -}}`
+			})
+			itShouldFailToRender(terraformCode, "salt must be at least 8 bytes long")
+		})
+		Context("when the input is not a string", func() {
+			BeforeEach(func() {
+				*template = `{{- True | argon2id -}}`
+			})
+			itShouldFailToRender(terraformCode, "True is not a string")
+		})
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- "thrown" | fail | argon2id -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
+	Context("merge", func() {
+		BeforeEach(func() {
+			*template = heredoc.Doc(`
+				{{- {"foo": "bar", "still": "untouched"} | merge({"foo": "fizz", "buzz": "bar"}) -}}
+			`)
 		})
-		AfterAll(func() {
-			os.RemoveAll(*directory)
+		itShouldSetTheExpectedResult(terraformCode, "{'buzz': 'bar', 'foo': 'bar', 'still': 'untouched'}")
+		Context("when merging with overrides", func() {
+			BeforeEach(func() {
+				*template = heredoc.Doc(`
+					{{- {"foo": "bar", "still": "untouched"} | merge({"foo": "fizz", "buzz": "bar"}, override=True) -}}
+				`)
+			})
+			itShouldSetTheExpectedResult(terraformCode, "{'buzz': 'bar', 'foo': 'fizz', 'still': 'untouched'}")
 		})
-		itShouldSetTheExpectedResult(terraformCode, path.Join(os.TempDir(), "abspath", "file.txt"))
-		Context("when the input is not a string", func() {
+		Context("when the input is not a dict", func() {
 			BeforeEach(func() {
-				*template = `{{- true | abspath -}}`
+				*template = `{{- [] | merge({}) -}}`
 			})
-			itShouldFailToRender(terraformCode, "True is not a string")
+			itShouldFailToRender(terraformCode, "\\[\\] is not a dict")
+		})
+		Context("when the argument is not a dict", func() {
+			BeforeEach(func() {
+				*template = `{{- {} | merge(True) -}}`
+			})
+			itShouldFailToRender(terraformCode, "True is not a dict")
+		})
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- "thrown" | fail | merge({}) -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
 		})
 	})
-	Context("distinct", func() {
+	Context("jsonpatch", func() {
 		BeforeEach(func() {
-			*template = `{{- ["a", "b", "a", "c", "d", "b"] | distinct -}}`
+			*template = heredoc.Doc(`
+				{{- {"spec": {"replicas": 1, "ports": [80]}} | jsonpatch([
+					{"op": "replace", "path": "/spec/replicas", "value": 3},
+					{"op": "add", "path": "/spec/ports/-", "value": 443},
+					{"op": "copy", "from": "/spec/ports/0", "path": "/spec/default"},
+					{"op": "test", "path": "/spec/default", "value": 80},
+				]) -}}
+			`)
 		})
-		itShouldSetTheExpectedResult(terraformCode, "['a', 'b', 'c', 'd']")
-		Context("when the input is a list of integers", func() {
+		itShouldSetTheExpectedResult(terraformCode, "{'spec': {'default': 80, 'ports': [80, 443], 'replicas': 3}}")
+		Context("when a test operation fails", func() {
 			BeforeEach(func() {
-				*template = `{{- [1,1,1,2,3,3,2,3] | distinct -}}`
+				*template = `{{- {"replicas": 1} | jsonpatch([{"op": "test", "path": "/replicas", "value": 2}]) -}}`
 			})
-			itShouldSetTheExpectedResult(terraformCode, "[1, 2, 3]")
+			itShouldFailToRender(terraformCode, "failed to apply test operation at index 0 of the patch: /replicas holds 1 instead of 2")
 		})
-		Context("when the input is a list of objects", func() {
+		Context("when the path does not exist", func() {
 			BeforeEach(func() {
-				*template = `{{- [{"one": 1}, {"two": 2}, {"one": 1}] | distinct -}}`
+				*template = `{{- {} | jsonpatch([{"op": "remove", "path": "/spec/replicas"}]) -}}`
 			})
-			itShouldSetTheExpectedResult(terraformCode, "[{'one': 1}, {'two': 2}]")
+			itShouldFailToRender(terraformCode, "failed to apply remove operation at index 0 of the patch: /spec/replicas does not exist")
 		})
-		Context("when the input is a list of lists", func() {
+		Context("when the path of a later test operation does not exist", func() {
 			BeforeEach(func() {
-				*template = `{{- [[1,2], [1,"two"], [1,"two"]] | distinct -}}`
+				*template = `{{- {"replicas": 1} | jsonpatch([{"op": "replace", "path": "/replicas", "value": 2}, {"op": "test", "path": "/image", "value": "nginx"}]) -}}`
 			})
-			itShouldSetTheExpectedResult(terraformCode, "[[1, 2], [1, 'two']]")
+			itShouldFailToRender(terraformCode, "failed to apply test operation at index 1 of the patch: /image does not exist")
 		})
-		Context("when the input is not a list", func() {
+		Context("when the operations are not a list", func() {
 			BeforeEach(func() {
-				*template = `{{- {} | distinct -}}`
+				*template = `{{- {} | jsonpatch(True) -}}`
 			})
-			itShouldFailToRender(terraformCode, "{} is not a list")
+			itShouldFailToRender(terraformCode, "True is not a list")
 		})
 		Context("when the input is an error", func() {
 			BeforeEach(func() {
-				*template = `{{- "thrown" | fail | distinct -}}`
+				*template = `{{- "thrown" | fail | jsonpatch([]) -}}`
 			})
 			itShouldFailToRender(terraformCode, "thrown")
 		})
 	})
-	Context("env", func() {
+	Context("mergepatch", func() {
 		BeforeEach(func() {
-			Must(os.Setenv("FOO", "BAR"))
-
-			*template = `{{- "FOO" | env -}}`
+			*template = `{{- {"spec": {"replicas": 1, "image": "nginx"}} | mergepatch({"spec": {"replicas": 3, "image": None}}) -}}`
 		})
-		AfterEach(func() {
-			Must(os.Unsetenv("FOO"))
+		itShouldSetTheExpectedResult(terraformCode, "{'spec': {'replicas': 3}}")
+		Context("when the patch is not a dict", func() {
+			BeforeEach(func() {
+				*template = `{{- {"spec": {}} | mergepatch([1]) -}}`
+			})
+			itShouldSetTheExpectedResult(terraformCode, "[1]")
 		})
-		itShouldSetTheExpectedResult(terraformCode, "BAR")
-		Context("when the environment variable does not exist", func() {
+		Context("when the input is an error", func() {
 			BeforeEach(func() {
-				*template = `{{- "NOPE" | env -}}`
+				*template = `{{- "thrown" | fail | mergepatch({}) -}}`
 			})
-			itShouldFailToRender(terraformCode, "failed to get 'NOPE' environment variable without default")
-			Context("but a default was defined", func() {
-				BeforeEach(func() {
-					*template = `{{- "NOPE" | env(default="BAR") -}}`
-				})
-				itShouldSetTheExpectedResult(terraformCode, "BAR")
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
+	Context("diff", func() {
+		BeforeEach(func() {
+			*template = `{{- {"replicas": 1, "ports": [80], "image": "nginx"} | diff({"replicas": 3, "ports": [80, 443]}) -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, "[{'op': 'remove', 'path': '/image'}, {'op': 'add', 'path': '/ports/1', 'value': 443}, {'op': 'replace', 'path': '/replicas', 'value': 3}]")
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- "thrown" | fail | diff({}) -}}`
 			})
+			itShouldFailToRender(terraformCode, "thrown")
 		})
-		Context("when the input is not a string", func() {
+	})
+	Context("query", func() {
+		BeforeEach(func() {
+			*template = heredoc.Doc(`
+				{{- [{"name": "a", "age": 30}, {"name": "b", "age": 20}, {"name": "c", "age": 40}] | query("[?age > ` + "`25`" + `].name") -}}
+			`)
+		})
+		itShouldSetTheExpectedResult(terraformCode, "['a', 'c']")
+		Context("when a field of a multiselect list is missing", func() {
 			BeforeEach(func() {
-				*template = `{{- True | env -}}`
+				*template = `{{- {"a": [{"n": "x"}]} | query("a[].[n, v]") }} {{ ({"a": [{"n": "x"}]} | query("a[].[n, v]"))[0][1] is none -}}`
 			})
-			itShouldFailToRender(terraformCode, "True is not a string")
+			itShouldSetTheExpectedResult(terraformCode, "[['x', ]] True")
+		})
+		Context("when using functions and pipes", func() {
+			BeforeEach(func() {
+				*template = heredoc.Doc(`
+					{{- [{"name": "a", "age": 30}, {"name": "b", "age": 20}] | query("sort_by(@, &age)[*].name | join(', ', @)") -}}
+				`)
+			})
+			itShouldSetTheExpectedResult(terraformCode, "b, a")
+		})
+		Context("when using multi-select hashes", func() {
+			BeforeEach(func() {
+				*template = `{{- {"foo": {"bar": 1, "baz": [1, 2, 3]}} | query("{bar: foo.bar, last: foo.baz[-1]}") -}}`
+			})
+			itShouldSetTheExpectedResult(terraformCode, "{'bar': 1, 'last': 3}")
+		})
+		Context("when the expression is invalid", func() {
+			BeforeEach(func() {
+				*template = `{{- {} | query("foo[?bar") -}}`
+			})
+			itShouldFailToRender(terraformCode, "syntax error at position 9 of foo\\[\\?bar: expected \\] but found end of expression")
+		})
+		Context("when a function is called with an invalid argument", func() {
+			BeforeEach(func() {
+				*template = `{{- {"foo": 1} | query("length(foo)") -}}`
+			})
+			itShouldFailToRender(terraformCode, "invalid type for the 1st argument of length\\(\\): expected string or array or object but got number")
 		})
 		Context("when the input is an error", func() {
 			BeforeEach(func() {
-				*template = `{{- "thrown" | fail | env -}}`
+				*template = `{{- "thrown" | fail | query("foo") -}}`
 			})
 			itShouldFailToRender(terraformCode, "thrown")
 		})
 	})
-	Context("tobase64", func() {
+	Context("fromini", func() {
 		BeforeEach(func() {
-			*template = `{{- "test" | tobase64 -}}`
+			*template = `{{- "top = 1\n; comment\n[section]\nkey = value ; inline\nquoted = \"a;b\"\nmulti = one\n  two" | fromini | tojson -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, `{"section":{"key":"value","multi":"one\ntwo","quoted":"a;b"},"top":"1"}`)
+		Context("when sections are indented", func() {
+			BeforeEach(func() {
+				*template = `{{- "[core]\n    editor = vim\n    pager = less\n      -R\n[user]\n\tname = me\n\temail = me@example.com" | fromini | tojson -}}`
+			})
+			itShouldSetTheExpectedResult(terraformCode, `{"core":{"editor":"vim","pager":"less\n-R"},"user":{"email":"me@example.com","name":"me"}}`)
 		})
-		itShouldSetTheExpectedResult(terraformCode, "dGVzdA==")
 		Context("when the input is not a string", func() {
 			BeforeEach(func() {
-				*template = `{{- True | tobase64 -}}`
+				*template = `{{- True | fromini -}}`
 			})
 			itShouldFailToRender(terraformCode, "True is not a string")
 		})
+		Context("when the input is not a valid INI document", func() {
+			BeforeEach(func() {
+				*template = `{{- "[nope" | fromini -}}`
+			})
+			itShouldFailToRender(terraformCode, "line 1: section header \\[nope is not closed")
+		})
 		Context("when the input is an error", func() {
 			BeforeEach(func() {
-				*template = `{{- "thrown" | fail | tobase64 -}}`
+				*template = `{{- "thrown" | fail | fromini -}}`
 			})
 			itShouldFailToRender(terraformCode, "thrown")
 		})
 	})
-	Context("frombase64", func() {
+	Context("toini", func() {
 		BeforeEach(func() {
-			*template = `{{- "dGVzdA==" | frombase64 -}}`
+			*template = `{{- {"section": {"b": " padded ", "a": 1}, "top": True} | toini -}}`
 		})
-		itShouldSetTheExpectedResult(terraformCode, "test")
-		Context("when the input is not a string", func() {
+		itShouldSetTheExpectedResult(terraformCode, heredoc.Doc(`
+			top = true
+
+			[section]
+			a = 1
+			b = " padded "
+		`))
+		Context("when the input is not a dict", func() {
 			BeforeEach(func() {
-				*template = `{{- True | frombase64 -}}`
+				*template = `{{- [] | toini -}}`
 			})
-			itShouldFailToRender(terraformCode, "True is not a string")
+			itShouldFailToRender(terraformCode, "\\[\\] is not a dict")
 		})
-		Context("when the input is not a valid base64 encoding", func() {
+		Context("when a section holds a nested value", func() {
 			BeforeEach(func() {
-				*template = `{{- "❌" | frombase64 -}}`
+				*template = `{{- {"section": {"nested": {}}} | toini -}}`
 			})
-			itShouldFailToRender(terraformCode, "failed to decode '❌' from base64")
+			itShouldFailToRender(terraformCode, "failed to encode section.nested")
 		})
 		Context("when the input is an error", func() {
 			BeforeEach(func() {
-				*template = `{{- "thrown" | fail | frombase64 -}}`
+				*template = `{{- "thrown" | fail | toini -}}`
 			})
 			itShouldFailToRender(terraformCode, "thrown")
 		})
 	})
-	Context("fromcsv", func() {
+	Context("fromdotenv", func() {
 		BeforeEach(func() {
-			*template = `{{- "a,b,c\n1,2,3\n4,5,6" | fromcsv -}}`
+			*template = `{{- "# comment\nexport FIRST=1\nSECOND='literal \\n'\nTHIRD=\"escaped\\nvalue\" # comment\nFOURTH=plain value # comment" | fromdotenv | tojson -}}`
 		})
-		itShouldSetTheExpectedResult(terraformCode, "[{'a': '1', 'b': '2', 'c': '3'}, {'a': '4', 'b': '5', 'c': '6'}]")
+		itShouldSetTheExpectedResult(terraformCode, `{"FIRST":"1","FOURTH":"plain value","SECOND":"literal \\n","THIRD":"escaped\nvalue"}`)
 		Context("when the input is not a string", func() {
 			BeforeEach(func() {
-				*template = `{{- True | fromcsv -}}`
+				*template = `{{- True | fromdotenv -}}`
 			})
 			itShouldFailToRender(terraformCode, "True is not a string")
 		})
-		Context("when the input is not a valid csv", func() {
+		Context("when the input is not a valid dotenv document", func() {
 			BeforeEach(func() {
-				*template = `{{- "nope,;s\nssss" | fromcsv -}}`
+				*template = `{{- "KEY=\"unterminated" | fromdotenv -}}`
 			})
-			itShouldFailToRender(terraformCode, "record on line 2: wrong number of fields")
+			itShouldFailToRender(terraformCode, "line 1: unterminated quoted value")
 		})
 		Context("when the input is an error", func() {
 			BeforeEach(func() {
-				*template = `{{- "thrown" | fail | fromcsv -}}`
+				*template = `{{- "thrown" | fail | fromdotenv -}}`
 			})
 			itShouldFailToRender(terraformCode, "thrown")
 		})
 	})
-	Context("fromtfvars", func() {
+	Context("todotenv", func() {
 		BeforeEach(func() {
-			*template = `{{- 'foo = "bar"\nobj = {\ntest = 123\n}' | fromtfvars -}}`
+			*template = `{{- {"SIMPLE": "value", "QUOTED": "with spaces and $dollar", "NUMBER": 42} | todotenv -}}`
 		})
-		itShouldSetTheExpectedResult(terraformCode, "{'foo': 'bar', 'obj': {'test': 123}}")
-		Context("when the input is not a string", func() {
+		itShouldSetTheExpectedResult(terraformCode, heredoc.Doc(`
+			NUMBER=42
+			QUOTED="with spaces and \$dollar"
+			SIMPLE=value
+		`))
+		Context("when the input is not a dict", func() {
 			BeforeEach(func() {
-				*template = `{{- True | fromtfvars -}}`
+				*template = `{{- [] | todotenv -}}`
 			})
-			itShouldFailToRender(terraformCode, "True is not a string")
+			itShouldFailToRender(terraformCode, "\\[\\] is not a dict")
 		})
-		Context("when the input is not a valid tfvars file", func() {
+		Context("when a key is not a valid variable name", func() {
 			BeforeEach(func() {
-				*template = `{{- "nope,;ssss" | fromtfvars -}}`
+				*template = `{{- {"not valid": 1} | todotenv -}}`
 			})
-			itShouldFailToRender(terraformCode, "failed to parse 'nope,;ssss' as tfvars")
+			itShouldFailToRender(terraformCode, "key \"not valid\" can not be represented in dotenv")
 		})
 		Context("when the input is an error", func() {
 			BeforeEach(func() {
-				*template = `{{- "thrown" | fail | fromtfvars -}}`
+				*template = `{{- "thrown" | fail | todotenv -}}`
 			})
 			itShouldFailToRender(terraformCode, "thrown")
 		})
 	})
-	Context("sha1", func() {
+	Context("tohcl", func() {
 		BeforeEach(func() {
-			*template = `{{- 'test' | sha1 -}}`
+			*template = `{{- {"name": "$${var.x}", "ports": [80, 443], "labels": {"app name": "web"}} | tohcl -}}`
 		})
-		itShouldSetTheExpectedResult(terraformCode, "a94a8fe5ccb19ba61c4c0873d391e987982fbbd3")
-		Context("when the input is not a string", func() {
+		itShouldSetTheExpectedResult(terraformCode, heredoc.Doc(`
+			{
+			  labels = {
+			    "app name" = "web"
+			  }
+			  name  = "$${var.x}"
+			  ports = [80, 443]
+			}`))
+		Context("when the block keyword argument is set", func() {
+			BeforeEach(func() {
+				*template = `{{- {"bucket": "logs", "lifecycle": {"prevent_destroy": true}, "rule": [{"id": "a"}, {"id": "b"}]} | tohcl(block="resource", labels=["aws_s3_bucket", "logs"], blocks=["lifecycle", "rule"]) -}}`
+			})
+			itShouldSetTheExpectedResult(terraformCode, heredoc.Doc(`
+				resource "aws_s3_bucket" "logs" {
+				  bucket = "logs"
+
+				  lifecycle {
+				    prevent_destroy = true
+				  }
+
+				  rule {
+				    id = "a"
+				  }
+
+				  rule {
+				    id = "b"
+				  }
+				}
+			`))
+		})
+		Context("when labels are set without a block", func() {
 			BeforeEach(func() {
-				*template = `{{- True | sha1 -}}`
+				*template = `{{- {} | tohcl(labels=["a"]) -}}`
 			})
-			itShouldFailToRender(terraformCode, "True is not a string")
+			itShouldFailToRender(terraformCode, "labels and blocks can only be set along with block")
+		})
+		Context("when a nested block is not a dict", func() {
+			BeforeEach(func() {
+				*template = `{{- {"lifecycle": True} | tohcl(block="resource", blocks=["lifecycle"]) -}}`
+			})
+			itShouldFailToRender(terraformCode, "block lifecycle is neither a dict nor a list of dicts")
 		})
 		Context("when the input is an error", func() {
 			BeforeEach(func() {
-				*template = `{{- "thrown" | fail | sha1 -}}`
+				*template = `{{- "thrown" | fail | tohcl -}}`
 			})
 			itShouldFailToRender(terraformCode, "thrown")
 		})
 	})
-	Context("sha256", func() {
+	Context("totfvars", func() {
 		BeforeEach(func() {
-			*template = `{{- 'test' | sha256 -}}`
+			*template = `{{- {"region": "eu-west-1", "replicas": 3, "template": "%%{ if true }$${x}%%{ endif }"} | totfvars | fromtfvars | tojson -}}`
 		})
-		itShouldSetTheExpectedResult(terraformCode, "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08")
+		itShouldSetTheExpectedResult(terraformCode, `{"region":"eu-west-1","replicas":3,"template":"%{ if true }${x}%{ endif }"}`)
+		Context("when the input is not a dict", func() {
+			BeforeEach(func() {
+				*template = `{{- [] | totfvars -}}`
+			})
+			itShouldFailToRender(terraformCode, "\\[\\] is not a dict")
+		})
+		Context("when nested keys are HCL keywords", func() {
+			BeforeEach(func() {
+				*template = `{{- {"c": {"for": 2, "if": [{"null": true}], "in": 1}} | totfvars | fromtfvars | tojson -}}`
+			})
+			itShouldSetTheExpectedResult(terraformCode, `{"c":{"for":2,"if":[{"null":true}],"in":1}}`)
+		})
+		Context("when a key is not a valid variable name", func() {
+			BeforeEach(func() {
+				*template = `{{- {"not valid": 1} | totfvars -}}`
+			})
+			itShouldFailToRender(terraformCode, "not valid is not a valid HCL identifier")
+		})
+	})
+	Context("fromproperties", func() {
+		BeforeEach(func() {
+			*template = `{{- "# comment\n! comment\na.b = 1\nc:2\nd 3\nlong = one \\\n    two\nunicode = caf\\u00e9" | fromproperties | tojson -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, `{"a.b":"1","c":"2","d":"3","long":"one two","unicode":"café"}`)
 		Context("when the input is not a string", func() {
 			BeforeEach(func() {
-				*template = `{{- True | sha256 -}}`
+				*template = `{{- True | fromproperties -}}`
 			})
 			itShouldFailToRender(terraformCode, "True is not a string")
 		})
+		Context("when the input has a malformed unicode escape", func() {
+			BeforeEach(func() {
+				*template = `{{- "key = \\u12" | fromproperties -}}`
+			})
+			itShouldFailToRender(terraformCode, "line 1: malformed \\\\uXXXX escape sequence")
+		})
 		Context("when the input is an error", func() {
 			BeforeEach(func() {
-				*template = `{{- "thrown" | fail | sha256 -}}`
+				*template = `{{- "thrown" | fail | fromproperties -}}`
 			})
 			itShouldFailToRender(terraformCode, "thrown")
 		})
 	})
-	Context("sha512", func() {
+	Context("toproperties", func() {
 		BeforeEach(func() {
-			*template = `{{- 'test' | sha512 -}}`
+			*template = `{{- {"with space": "a=b", "unicode": "café", "number": 1} | toproperties -}}`
 		})
-		itShouldSetTheExpectedResult(terraformCode, "ee26b0dd4af7e749aa1a8ee3c10ae9923f618980772e473f8819a5d4940e0db27ac185f8a0e1d5f84f88bc887fd67b143732c304cc5fa9ad8e6f57f50028a8ff")
-		Context("when the input is not a string", func() {
+		itShouldSetTheExpectedResult(terraformCode, heredoc.Doc(`
+			number=1
+			unicode=caf\u00E9
+			with\ space=a\=b
+		`))
+		Context("when the input is not a dict", func() {
 			BeforeEach(func() {
-				*template = `{{- True | sha512 -}}`
+				*template = `{{- [] | toproperties -}}`
 			})
-			itShouldFailToRender(terraformCode, "True is not a string")
+			itShouldFailToRender(terraformCode, "\\[\\] is not a dict")
+		})
+		Context("when a value is not a scalar", func() {
+			BeforeEach(func() {
+				*template = `{{- {"key": []} | toproperties -}}`
+			})
+			itShouldFailToRender(terraformCode, "failed to encode key")
 		})
 		Context("when the input is an error", func() {
 			BeforeEach(func() {
-				*template = `{{- "thrown" | fail | sha512 -}}`
+				*template = `{{- "thrown" | fail | toproperties -}}`
 			})
 			itShouldFailToRender(terraformCode, "thrown")
 		})
 	})
-	Context("md5", func() {
+	Context("fromxml", func() {
 		BeforeEach(func() {
-			*template = `{{- 'test' | md5 -}}`
+			*template = `{{- "<servers><server id=\"a\">first</server><server id=\"b\"><name>second</name></server><empty/></servers>" | fromxml | tojson -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, `{"servers":{"empty":"","server":[{"#text":"first","@id":"a"},{"@id":"b","name":"second"}]}}`)
+		Context("when setting a custom convention", func() {
+			BeforeEach(func() {
+				*template = `{{- "<server id=\"a\">first</server>" | fromxml(attribute_prefix="_", text_key="value") | tojson -}}`
+			})
+			itShouldSetTheExpectedResult(terraformCode, `{"server":{"_id":"a","value":"first"}}`)
+		})
+		Context("when empty elements are repeated", func() {
+			BeforeEach(func() {
+				*template = `{{- "<a><b/><b></b></a>" | fromxml }} {{ "<a><b/><b></b></a>" | fromxml | tojson -}}`
+			})
+			itShouldSetTheExpectedResult(terraformCode, `{'a': {'b': ['', '']}} {"a":{"b":["",""]}}`)
 		})
-		itShouldSetTheExpectedResult(terraformCode, "098f6bcd4621d373cade4e832627b4f6")
 		Context("when the input is not a string", func() {
 			BeforeEach(func() {
-				*template = `{{- True | md5 -}}`
+				*template = `{{- True | fromxml -}}`
 			})
-			itShouldFailToRender(terraformCode, "invalid call to filter 'md5': True is not a string")
+			itShouldFailToRender(terraformCode, "True is not a string")
+		})
+		Context("when the input is not a valid XML document", func() {
+			BeforeEach(func() {
+				*template = `{{- "<a><b></a>" | fromxml -}}`
+			})
+			itShouldFailToRender(terraformCode, "line 1: unexpected closing element </a>")
+		})
+		Context("when the input has several root elements", func() {
+			BeforeEach(func() {
+				*template = `{{- "<a/><b/>" | fromxml -}}`
+			})
+			itShouldFailToRender(terraformCode, "document has more than one root element")
 		})
 		Context("when the input is an error", func() {
 			BeforeEach(func() {
-				*template = `{{- "thrown" | fail | md5 -}}`
+				*template = `{{- "thrown" | fail | fromxml -}}`
 			})
 			itShouldFailToRender(terraformCode, "thrown")
 		})
 	})
-	Context("merge", func() {
+	Context("toxml", func() {
 		BeforeEach(func() {
-			*template = heredoc.Doc(`
-				{{- {"foo": "bar", "still": "untouched"} | merge({"foo": "fizz", "buzz": "bar"}) -}}
-			`)
+			*template = `{{- {"servers": {"@region": "eu", "server": [{"@id": "a", "#text": "first"}, {"name": "x<y"}], "empty": None}} | toxml -}}`
 		})
-		itShouldSetTheExpectedResult(terraformCode, "{'buzz': 'bar', 'foo': 'bar', 'still': 'untouched'}")
-		Context("when merging with overrides", func() {
+		itShouldSetTheExpectedResult(terraformCode, heredoc.Doc(`
+			<?xml version="1.0" encoding="UTF-8"?>
+			<servers region="eu">
+			  <empty/>
+			  <server id="a">first</server>
+			  <server>
+			    <name>x&lt;y</name>
+			  </server>
+			</servers>
+		`))
+		Context("when setting the root element and formatting options", func() {
 			BeforeEach(func() {
-				*template = heredoc.Doc(`
-					{{- {"foo": "bar", "still": "untouched"} | merge({"foo": "fizz", "buzz": "bar"}, override=True) -}}
-				`)
+				*template = `{{- {"_version": 1, "name": "value"} | toxml(root="project", attribute_prefix="_", indent=0, declaration=False) -}}`
 			})
-			itShouldSetTheExpectedResult(terraformCode, "{'buzz': 'bar', 'foo': 'fizz', 'still': 'untouched'}")
+			itShouldSetTheExpectedResult(terraformCode, `<project version="1"><name>value</name></project>`)
+		})
+		Context("when the input has several keys and no root is set", func() {
+			BeforeEach(func() {
+				*template = `{{- {"a": 1, "b": 2} | toxml -}}`
+			})
+			itShouldFailToRender(terraformCode, "must hold exactly one key to be used as root element")
 		})
 		Context("when the input is not a dict", func() {
 			BeforeEach(func() {
-				*template = `{{- [] | merge({}) -}}`
+				*template = `{{- [] | toxml -}}`
 			})
 			itShouldFailToRender(terraformCode, "\\[\\] is not a dict")
 		})
-		Context("when the argument is not a dict", func() {
+		Context("when the input is an error", func() {
 			BeforeEach(func() {
-				*template = `{{- {} | merge(True) -}}`
+				*template = `{{- "thrown" | fail | toxml -}}`
 			})
-			itShouldFailToRender(terraformCode, "True is not a dict")
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
+	Context("tocsv", func() {
+		BeforeEach(func() {
+			*template = `{{- [{"name": "web", "port": 443}, {"name": "db, primary"}] | tocsv -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, "name,port\nweb,443\n\"db, primary\",\n")
+		Context("when setting columns without header", func() {
+			BeforeEach(func() {
+				*template = `{{- [{"name": "web", "port": 443, "tls": True}] | tocsv(columns=["port", "name"], header=False, delimiter=";") -}}`
+			})
+			itShouldSetTheExpectedResult(terraformCode, "443;web\n")
+		})
+		Context("when the input is not a list", func() {
+			BeforeEach(func() {
+				*template = `{{- "nope" | tocsv -}}`
+			})
+			itShouldFailToRender(terraformCode, "nope is not a list")
+		})
+		Context("when a row is not a dict", func() {
+			BeforeEach(func() {
+				*template = `{{- [1] | tocsv -}}`
+			})
+			itShouldFailToRender(terraformCode, "1st row 1 is not a dict")
+		})
+		Context("when a value is not a scalar", func() {
+			BeforeEach(func() {
+				*template = `{{- [{"a": [1]}] | tocsv -}}`
+			})
+			itShouldFailToRender(terraformCode, "1st row, column a: \\[1\\] is not a scalar value")
 		})
 		Context("when the input is an error", func() {
 			BeforeEach(func() {
-				*template = `{{- "thrown" | fail | merge({}) -}}`
+				*template = `{{- "thrown" | fail | tocsv -}}`
 			})
 			itShouldFailToRender(terraformCode, "thrown")
 		})
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"time"
 
 	"github.com/MakeNowJust/heredoc"
 	"github.com/google/uuid"
@@ -182,4 +183,49 @@ var _ = Context("globals", func() {
 			itShouldFailToRender(terraformCode, "True is not a string")
 		})
 	})
+	Context("query", func() {
+		BeforeEach(func() {
+			*template = `{{- query([{"name": "a", "age": 30}, {"name": "b", "age": 20}], "max_by(@, &age).name") -}}`
+		})
+		itShouldSetTheExpectedResult(terraformCode, `a`)
+		Context("when the expression is not a string", func() {
+			BeforeEach(func() {
+				*template = `{{- query({}, True) -}}`
+			})
+			itShouldFailToRender(terraformCode, "True is not a string")
+		})
+		Context("when the expression is invalid", func() {
+			BeforeEach(func() {
+				*template = `{{- query({}, "foo.") -}}`
+			})
+			itShouldFailToRender(terraformCode, "syntax error at position 5 of foo.: expected an identifier")
+		})
+	})
+	Context("now", func() {
+		BeforeEach(func() {
+			*template = `{{- now() -}}`
+		})
+		It("should render the expected content", func() {
+			resource.UnitTest(GinkgoT(), resource.TestCase{
+				ProtoV6ProviderFactories: testProviderFactory,
+				Steps: []resource.TestStep{
+					{
+						Config: *terraformCode,
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestCheckResourceAttrWith("data.jinja_template.test", "result", func(got string) error {
+								_, err := time.Parse(time.RFC3339, got)
+								return err
+							}),
+						),
+					},
+				},
+			})
+		})
+		Context("when the format is invalid", func() {
+			BeforeEach(func() {
+				*template = `{{- now(format="%Q") -}}`
+			})
+			itShouldFailToRender(terraformCode, "unsupported directive %Q in format %Q")
+		})
+	})
 })
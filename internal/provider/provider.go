@@ -2,10 +2,13 @@ package provider
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/nikolalohinski/terraform-provider-jinja/v2/lib"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
@@ -26,9 +29,17 @@ type jinjaProvider struct {
 
 type jinjaProviderModel struct {
 	StrictUndefined types.Bool   `tfsdk:"strict_undefined"`
+	StrictParsing   types.Bool   `tfsdk:"strict_parsing"`
 	TrimBlocks      types.Bool   `tfsdk:"trim_blocks"`
 	LeftStripBlocks types.Bool   `tfsdk:"left_strip_blocks"`
 	Delimiters      types.Object `tfsdk:"delimiters"`
+	Decryption      types.Object `tfsdk:"decryption"`
+	FrozenTime      types.String `tfsdk:"frozen_time"`
+}
+
+type jinjaDecryptionModel struct {
+	Keyring       types.String `tfsdk:"keyring"`
+	PassphraseEnv types.String `tfsdk:"passphrase_env"`
 }
 
 type jinjaDelimitersModel struct {
@@ -71,12 +82,29 @@ func (p *jinjaProvider) Schema(ctx context.Context, req provider.SchemaRequest,
 					},
 				},
 			},
+			"decryption": schema.SingleNestedBlock{
+				MarkdownDescription: "OpenPGP settings used to decrypt the `context` blocks of all templates that set `encrypted = true`",
+				Attributes: map[string]schema.Attribute{
+					"keyring": schema.StringAttribute{
+						Optional:            true,
+						MarkdownDescription: "Path to an ASCII armored or binary keyring holding the private keys to decrypt messages with",
+					},
+					"passphrase_env": schema.StringAttribute{
+						Optional:            true,
+						MarkdownDescription: "Name of the environment variable holding the passphrase of symmetrically encrypted messages, also used to unlock the private keys of the keyring protected by one",
+					},
+				},
+			},
 		},
 		Attributes: map[string]schema.Attribute{
 			"strict_undefined": schema.BoolAttribute{
 				Optional:            true,
 				MarkdownDescription: "Set to `true` to fail on missing items and attribute for all templates",
 			},
+			"strict_parsing": schema.BoolAttribute{
+				Optional:            true,
+				MarkdownDescription: "Set to `true` to fail on duplicate keys, non string keys and unquoted values interpreted differently across YAML versions, such as `NO`, `0755` or `1:30`, in the `json` and `yaml` contexts of all templates",
+			},
 			"trim_blocks": schema.BoolAttribute{
 				Optional:            true,
 				MarkdownDescription: "Set to `true` the first newline after a block is removed for all templates",
@@ -85,6 +113,10 @@ func (p *jinjaProvider) Schema(ctx context.Context, req provider.SchemaRequest,
 				Optional:            true,
 				MarkdownDescription: "Set to `true` leading spaces and tabs are stripped from the start of a line to a block for all templates",
 			},
+			"frozen_time": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "RFC 3339 timestamp, such as `2024-01-01T00:00:00Z`, returned by the `now` function instead of the current time in all templates so that they render deterministically",
+			},
 		},
 	}
 }
@@ -99,6 +131,7 @@ func (p *jinjaProvider) Configure(ctx context.Context, req provider.ConfigureReq
 	}
 	configuration := lib.Configuration{
 		StrictUndefined: data.StrictUndefined.ValueBool(),
+		StrictParsing:   data.StrictParsing.ValueBool(),
 		LeftStripBlocks: data.LeftStripBlocks.ValueBool(),
 		TrimBlocks:      data.TrimBlocks.ValueBool(),
 		Delimiters: lib.Delimiters{
@@ -135,6 +168,30 @@ func (p *jinjaProvider) Configure(ctx context.Context, req provider.ConfigureReq
 			configuration.Delimiters.CommentEnd = delimiters.CommentEnd.ValueString()
 		}
 	}
+	if !data.Decryption.IsNull() && !data.Decryption.IsUnknown() {
+		var decryption jinjaDecryptionModel
+		resp.Diagnostics.Append(data.Decryption.As(ctx, &decryption, basetypes.ObjectAsOptions{})...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		configuration.Decryption = lib.Decryption{
+			Keyring:       decryption.Keyring.ValueString(),
+			PassphraseEnv: decryption.PassphraseEnv.ValueString(),
+		}
+	}
+
+	if !data.FrozenTime.IsNull() && !data.FrozenTime.IsUnknown() {
+		frozenTime, err := time.Parse(time.RFC3339, data.FrozenTime.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("frozen_time"),
+				"Invalid frozen time",
+				fmt.Sprintf("%s is not a valid RFC 3339 timestamp", data.FrozenTime.ValueString()),
+			)
+			return
+		}
+		configuration.FrozenTime = frozenTime
+	}
 
 	resp.DataSourceData = configuration
 }
@@ -87,6 +87,98 @@ var _ = Context("tests", func() {
 			itShouldFailToRender(terraformCode, "invalid call to test 'empty': True is neither a list, a dict nor a string")
 		})
 	})
+	Context("has_path", func() {
+		BeforeEach(func() {
+			*template = `{{- input is has_path("spec.containers[0].image") -}}`
+		})
+		Context("when the path exists", func() {
+			BeforeEach(func() {
+				*context = `input = { spec = { containers = [{ image = "nginx" }] } }`
+			})
+			itShouldSetTheExpectedResult(terraformCode, "True")
+		})
+		Context("when the path does not exist", func() {
+			BeforeEach(func() {
+				*context = `input = { spec = { containers = [] } }`
+			})
+			itShouldSetTheExpectedResult(terraformCode, "False")
+		})
+		Context("when the path traverses a scalar", func() {
+			BeforeEach(func() {
+				*context = `input = { spec = "text" }`
+			})
+			itShouldFailToRender(terraformCode, "can not traverse spec.containers\\[0\\].image: spec holds the scalar text instead of a dict")
+		})
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- ("thrown" | fail) is has_path("spec") -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
+	Context("cidr_contains", func() {
+		BeforeEach(func() {
+			*template = `{{- input is cidr_contains("10.1.2.3") -}}`
+		})
+		Context("when the network contains the address", func() {
+			BeforeEach(func() {
+				*context = `input = "10.0.0.0/8"`
+			})
+			itShouldSetTheExpectedResult(terraformCode, "True")
+		})
+		Context("when the network does not contain the address", func() {
+			BeforeEach(func() {
+				*context = `input = "192.168.0.0/16"`
+			})
+			itShouldSetTheExpectedResult(terraformCode, "False")
+		})
+		Context("when the input is not a CIDR notation", func() {
+			BeforeEach(func() {
+				*context = `input = "foo"`
+			})
+			itShouldFailToRender(terraformCode, "foo is not a valid CIDR notation")
+		})
+	})
+	Context("ipv4", func() {
+		BeforeEach(func() {
+			*template = `{{- input is ipv4 -}}`
+		})
+		Context("when the input is an IPv4 address", func() {
+			BeforeEach(func() {
+				*context = `input = "10.0.0.1"`
+			})
+			itShouldSetTheExpectedResult(terraformCode, "True")
+		})
+		Context("when the input is an IPv6 address", func() {
+			BeforeEach(func() {
+				*context = `input = "fd00::1"`
+			})
+			itShouldSetTheExpectedResult(terraformCode, "False")
+		})
+		Context("when the input is not a string", func() {
+			BeforeEach(func() {
+				*context = `input = true`
+			})
+			itShouldSetTheExpectedResult(terraformCode, "False")
+		})
+	})
+	Context("ipv6", func() {
+		BeforeEach(func() {
+			*template = `{{- input is ipv6 -}}`
+		})
+		Context("when the input is an IPv6 network", func() {
+			BeforeEach(func() {
+				*context = `input = "fd00::/8"`
+			})
+			itShouldSetTheExpectedResult(terraformCode, "True")
+		})
+		Context("when the input is not an IP address", func() {
+			BeforeEach(func() {
+				*context = `input = "foo"`
+			})
+			itShouldSetTheExpectedResult(terraformCode, "False")
+		})
+	})
 	Context("match", func() {
 		BeforeEach(func() {
 			*template = `{{- input is match("^f(o)+$") -}}`
@@ -123,4 +215,117 @@ var _ = Context("tests", func() {
 			itShouldFailToRender(terraformCode, "True is not a string")
 		})
 	})
+	Context("query", func() {
+		BeforeEach(func() {
+			*template = `{{- input is query("replicas > ` + "`1`" + `") -}}`
+		})
+		Context("when the result is truthy", func() {
+			BeforeEach(func() {
+				*context = `input = { replicas = 3 }`
+			})
+			itShouldSetTheExpectedResult(terraformCode, "True")
+		})
+		Context("when the result is falsy", func() {
+			BeforeEach(func() {
+				*context = `input = { replicas = 1 }`
+			})
+			itShouldSetTheExpectedResult(terraformCode, "False")
+		})
+		Context("when the expression is invalid", func() {
+			BeforeEach(func() {
+				*context = `input = {}`
+				*template = `{{- input is query("replicas >") -}}`
+			})
+			itShouldFailToRender(terraformCode, "invalid call to test 'query': syntax error at position 11 of replicas >: incomplete expression")
+		})
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- ("thrown" | fail) is query("foo") -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
+	Context("subset", func() {
+		BeforeEach(func() {
+			*template = `{{- input is subset(["web", "db", "cache"]) -}}`
+		})
+		Context("when all the elements are held by the other list", func() {
+			BeforeEach(func() {
+				*context = `input = ["db", "web", "db"]`
+			})
+			itShouldSetTheExpectedResult(terraformCode, "True")
+		})
+		Context("when an element is not held by the other list", func() {
+			BeforeEach(func() {
+				*context = `input = ["db", "queue"]`
+			})
+			itShouldSetTheExpectedResult(terraformCode, "False")
+		})
+		Context("when the input is not a list", func() {
+			BeforeEach(func() {
+				*context = `input = "db"`
+			})
+			itShouldFailToRender(terraformCode, "db is not a list")
+		})
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- ("thrown" | fail) is subset([]) -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
+	Context("superset", func() {
+		BeforeEach(func() {
+			*template = `{{- input is superset([{"name": "web"}, {"name": "db"}]) -}}`
+		})
+		Context("when all the elements of the other list are held", func() {
+			BeforeEach(func() {
+				*context = `input = [{ name = "db" }, { name = "cache" }, { name = "web" }]`
+			})
+			itShouldSetTheExpectedResult(terraformCode, "True")
+		})
+		Context("when an element of the other list is not held", func() {
+			BeforeEach(func() {
+				*context = `input = [{ name = "db" }]`
+			})
+			itShouldSetTheExpectedResult(terraformCode, "False")
+		})
+		Context("when the argument is not a list", func() {
+			BeforeEach(func() {
+				*context = `input = []`
+				*template = `{{- input is superset("db") -}}`
+			})
+			itShouldFailToRender(terraformCode, "db is not a list")
+		})
+	})
+	Context("version", func() {
+		BeforeEach(func() {
+			*template = `{{- input is version("~> 1.25") -}}`
+		})
+		Context("when the version satisfies the constraint", func() {
+			BeforeEach(func() {
+				*context = `input = "1.27.4"`
+			})
+			itShouldSetTheExpectedResult(terraformCode, "True")
+		})
+		Context("when the version does not satisfy the constraint", func() {
+			BeforeEach(func() {
+				*context = `input = "2.0.0"`
+			})
+			itShouldSetTheExpectedResult(terraformCode, "False")
+		})
+		Context("when the constraint is invalid", func() {
+			BeforeEach(func() {
+				*context = `input = "1.27.4"`
+				*template = `{{- input is version("latest") -}}`
+			})
+			itShouldFailToRender(terraformCode, "latest is not a valid version constraint")
+		})
+		Context("when the input is an error", func() {
+			BeforeEach(func() {
+				*template = `{{- ("thrown" | fail) is version("~> 1.25") -}}`
+			})
+			itShouldFailToRender(terraformCode, "thrown")
+		})
+	})
 })
@@ -9,6 +9,7 @@ type Context struct {
 	Source        Source                     `json:"template"`
 	Configuration Configuration              `json:"configuration"`
 	Values        []Values                   `json:"values,omitempty"`
+	Overrides     []Override                 `json:"overrides,omitempty"`
 	Schemas       map[string]json.RawMessage `json:"schemas,omitempty"`
 	Timeout       time.Duration              `json:"render_timeout,omitempty"`
 }
@@ -18,15 +19,36 @@ type Source struct {
 }
 
 type Values struct {
-	Data []byte `json:"data"`
-	Type string `json:"type"`
+	Data           []byte             `json:"data"`
+	Type           string             `json:"type"`
+	File           string             `json:"file,omitempty"`
+	Key            string             `json:"key,omitempty"`
+	Tabular        TabularOptions     `json:"tabular"`
+	Environment    EnvironmentOptions `json:"environment"`
+	AllowSensitive bool               `json:"allow_sensitive"`
+	Render         bool               `json:"render"`
+	YAMLTags       bool               `json:"yaml_tags"`
+	Encrypted      bool               `json:"encrypted"`
+}
+
+type Override struct {
+	Path  string `json:"path"`
+	Value string `json:"value"`
+	Type  string `json:"type,omitempty"`
 }
 
 type Configuration struct {
 	StrictUndefined bool       `json:"strict_undefined"`
+	StrictParsing   bool       `json:"strict_parsing"`
 	Delimiters      Delimiters `json:"delimiters"`
 	LeftStripBlocks bool       `json:"left_strip_blocks"`
 	TrimBlocks      bool       `json:"trim_blocks"`
+	Decryption      Decryption `json:"decryption"`
+	FrozenTime      time.Time  `json:"frozen_time,omitempty"`
+}
+type Decryption struct {
+	Keyring       string `json:"keyring,omitempty"`
+	PassphraseEnv string `json:"passphrase_env,omitempty"`
 }
 type Delimiters struct {
 	BlockStart    string `json:"block_start"`
@@ -0,0 +1,95 @@
+package lib
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+)
+
+// decryptPGP decrypts an ASCII armored or binary OpenPGP message with the private keys of the configured
+// keyring or, for symmetrically encrypted messages, with the configured passphrase. The passphrase is also used
+// to unlock private keys protected by one.
+func decryptPGP(data []byte, decryption Decryption) ([]byte, error) {
+	var passphrase []byte
+	if decryption.PassphraseEnv != "" {
+		value, ok := os.LookupEnv(decryption.PassphraseEnv)
+		if !ok {
+			return nil, fmt.Errorf("failed to get '%s' environment variable holding the passphrase", decryption.PassphraseEnv)
+		}
+		passphrase = []byte(value)
+	}
+
+	keyring := openpgp.EntityList{}
+	if decryption.Keyring != "" {
+		content, err := os.ReadFile(decryption.Keyring)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read keyring: %s", err)
+		}
+		if keyring, err = readKeyRing(content); err != nil {
+			return nil, fmt.Errorf("failed to parse keyring %s: %s", decryption.Keyring, err)
+		}
+	}
+	if len(keyring) == 0 && passphrase == nil {
+		return nil, errors.New("neither a keyring nor a passphrase is configured to decrypt it")
+	}
+
+	var message io.Reader = bytes.NewReader(data)
+	if isArmored(data) {
+		block, err := armor.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode armored message: %s", err)
+		}
+		message = block.Body
+	}
+
+	// The prompt is called again as long as decryption fails, so it only hands the passphrase out once
+	prompted := false
+	prompt := func(keys []openpgp.Key, symmetric bool) ([]byte, error) {
+		if prompted || passphrase == nil {
+			if symmetric {
+				return nil, errors.New("the passphrase is missing or incorrect")
+			}
+			if passphrase == nil && len(keys) > 0 {
+				return nil, fmt.Errorf("private key %s is protected and no passphrase is configured", keys[0].PrivateKey.KeyIdString())
+			}
+			return nil, errors.New("no private key of the keyring can decrypt it")
+		}
+		prompted = true
+		if symmetric {
+			return passphrase, nil
+		}
+		for _, key := range keys {
+			if key.PrivateKey != nil && key.PrivateKey.Encrypted {
+				if err := key.PrivateKey.Decrypt(passphrase); err != nil {
+					return nil, fmt.Errorf("failed to unlock private key %s: %s", key.PrivateKey.KeyIdString(), err)
+				}
+			}
+		}
+		return nil, nil
+	}
+	details, err := openpgp.ReadMessage(message, keyring, prompt, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt message: %s", err)
+	}
+	plaintext, err := io.ReadAll(details.UnverifiedBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt message: %s", err)
+	}
+	return plaintext, nil
+}
+
+func readKeyRing(content []byte) (openpgp.EntityList, error) {
+	if isArmored(content) {
+		return openpgp.ReadArmoredKeyRing(bytes.NewReader(content))
+	}
+	return openpgp.ReadKeyRing(bytes.NewReader(content))
+}
+
+func isArmored(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte("-----BEGIN PGP"))
+}
@@ -0,0 +1,129 @@
+package lib
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	dotenvKeyPattern        = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+	dotenvUnquotedPattern   = regexp.MustCompile(`^[A-Za-z0-9_./:@%+,=-]*$`)
+	dotenvDoubleQuotedQuote = strings.NewReplacer("$", "\\$", "`", "\\`")
+)
+
+// decodeDotEnv parses dotenv formatted data into a flat map of strings. Lines can be prefixed with
+// `export`, values can be unquoted, single quoted to be taken literally or double quoted to use escape
+// sequences. Both quoted forms can span several lines. Variables are not expanded.
+func decodeDotEnv(data []byte) (map[string]interface{}, error) {
+	content := strings.ReplaceAll(string(data), "\r\n", "\n")
+	values := make(map[string]interface{})
+	line := 1
+	for index := 0; index < len(content); {
+		end := strings.IndexByte(content[index:], '\n')
+		if end < 0 {
+			end = len(content)
+		} else {
+			end += index
+		}
+		trimmed := strings.TrimSpace(content[index:end])
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			index = end + 1
+			line++
+			continue
+		}
+		separator := strings.IndexByte(trimmed, '=')
+		if separator < 0 {
+			return nil, fmt.Errorf("line %d: expected a 'KEY=value' pair but got: %s", line, trimmed)
+		}
+		key := strings.TrimSpace(trimmed[:separator])
+		if strings.HasPrefix(key, "export ") || strings.HasPrefix(key, "export\t") {
+			key = strings.TrimSpace(key[len("export"):])
+		}
+		if !dotenvKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("line %d: invalid key %q", line, key)
+		}
+		start := index + strings.IndexByte(content[index:end], '=') + 1
+		for start < end && (content[start] == ' ' || content[start] == '\t') {
+			start++
+		}
+		if start == end {
+			values[key] = ""
+			index = end + 1
+			line++
+			continue
+		}
+		switch content[start] {
+		case '"', '\'':
+			value, consumed, err := decodeDotEnvQuoted(content[start:])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s", line, err)
+			}
+			line += strings.Count(content[start:start+consumed], "\n")
+			index = start + consumed
+			end = strings.IndexByte(content[index:], '\n')
+			if end < 0 {
+				end = len(content)
+			} else {
+				end += index
+			}
+			if rest := strings.TrimSpace(content[index:end]); rest != "" && !strings.HasPrefix(rest, "#") {
+				return nil, fmt.Errorf("line %d: unexpected characters after quoted value: %s", line, rest)
+			}
+			values[key] = value
+		default:
+			value := content[start:end]
+			for position := 1; position < len(value); position++ {
+				if value[position] == '#' && (value[position-1] == ' ' || value[position-1] == '\t') {
+					value = value[:position]
+					break
+				}
+			}
+			values[key] = strings.TrimSpace(value)
+		}
+		index = end + 1
+		line++
+	}
+	return values, nil
+}
+
+func decodeDotEnvQuoted(content string) (string, int, error) {
+	quote := content[0]
+	builder := strings.Builder{}
+	for index := 1; index < len(content); index++ {
+		switch {
+		case content[index] == quote:
+			return builder.String(), index + 1, nil
+		case quote == '"' && content[index] == '\\' && index+1 < len(content):
+			if unescaped, ok := doubleQuotedEscapes[content[index+1]]; ok {
+				builder.WriteByte(unescaped)
+				index++
+				continue
+			}
+			builder.WriteByte(content[index])
+		default:
+			builder.WriteByte(content[index])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated quoted value starting with %c", quote)
+}
+
+// encodeDotEnv serializes a flat map of scalars into dotenv formatted data with sorted keys. Values
+// that contain characters outside of a conservative safe set are double quoted.
+func encodeDotEnv(values map[string]interface{}) (string, error) {
+	builder := strings.Builder{}
+	for _, key := range sortedKeys(values) {
+		if !dotenvKeyPattern.MatchString(key) {
+			return "", fmt.Errorf("key %q can not be represented in dotenv", key)
+		}
+		value, err := scalarToString(values[key])
+		if err != nil {
+			return "", fmt.Errorf("failed to encode %s: %s", key, err)
+		}
+		if !dotenvUnquotedPattern.MatchString(value) {
+			value = dotenvDoubleQuotedQuote.Replace(quoteDoubleQuoted(value))
+		}
+		builder.WriteString(key + "=" + value + "\n")
+	}
+	return builder.String(), nil
+}
@@ -0,0 +1,71 @@
+package lib
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	json "github.com/json-iterator/go"
+	"gopkg.in/yaml.v3"
+)
+
+const defaultEnvironmentSeparator = "__"
+
+// EnvironmentOptions controls which environment variables are loaded by environment contexts and how their
+// names are split into nested keys.
+type EnvironmentOptions struct {
+	Prefix    string `json:"prefix,omitempty"`
+	Separator string `json:"separator,omitempty"`
+}
+
+// decodeEnvironment loads the environment variables whose name starts with the configured prefix. The rest of
+// each name is lower cased and split on the separator into nested keys, so that `APP_DB__HOST` is loaded as
+// `db.host` with the `APP_` prefix. Values holding valid JSON are decoded, and kept as strings otherwise.
+func decodeEnvironment(options EnvironmentOptions) (map[string]interface{}, error) {
+	if options.Prefix == "" {
+		return nil, fmt.Errorf("a non-empty prefix is required to load the environment")
+	}
+	separator := options.Separator
+	if separator == "" {
+		separator = defaultEnvironmentSeparator
+	}
+	variables := make(map[string]string)
+	names := []string{}
+	for _, variable := range os.Environ() {
+		name, value, _ := strings.Cut(variable, "=")
+		if !strings.HasPrefix(name, options.Prefix) || name == options.Prefix {
+			continue
+		}
+		variables[name] = value
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	layer := make(map[string]interface{})
+	for _, name := range names {
+		segments := []pathSegment{}
+		for _, key := range strings.Split(strings.TrimPrefix(name, options.Prefix), separator) {
+			if key == "" {
+				return nil, fmt.Errorf("environment variable %s holds an empty key when split on %s", name, separator)
+			}
+			segments = append(segments, pathSegment{Key: strings.ToLower(key)})
+		}
+		if _, err := setPath(layer, segments, decodeEnvironmentValue(variables[name])); err != nil {
+			return nil, fmt.Errorf("environment variable %s: %s", name, err)
+		}
+	}
+	return layer, nil
+}
+
+func decodeEnvironmentValue(value string) interface{} {
+	// Validate JSON format before unmarshalling with YAML decoder to avoid casting ints to floats
+	if err := json.Unmarshal([]byte(value), new(interface{})); err != nil {
+		return value
+	}
+	var decoded interface{}
+	if err := yaml.Unmarshal([]byte(value), &decoded); err != nil {
+		return value
+	}
+	return decoded
+}
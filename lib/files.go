@@ -0,0 +1,100 @@
+package lib
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	"github.com/yargevad/filepathx"
+)
+
+var extensionFormats = map[string]valuesFormat{
+	".json":       FormatJSON,
+	".yaml":       FormatYAML,
+	".yml":        FormatYAML,
+	".toml":       FormatTOML,
+	".tfvars":     FormatTFVars,
+	".ini":        FormatINI,
+	".env":        FormatDotEnv,
+	".properties": FormatProperties,
+	".xml":        FormatXML,
+	".csv":        FormatCSV,
+	".tsv":        FormatTSV,
+	".ndjson":     FormatNDJSON,
+	".jsonl":      FormatNDJSON,
+	".tfstate":    FormatTFState,
+}
+
+// encryptedExtensions are skipped to infer the type of encrypted layers, such as `secrets.yaml.asc`.
+var encryptedExtensions = map[string]bool{
+	".asc": true,
+	".gpg": true,
+	".pgp": true,
+}
+
+// ExpandFiles replaces every values layer reading from a file by its content. File paths are relative to the
+// given directory and can be glob patterns, in which case each matching file becomes a layer of its own in
+// lexicographic order. When a layer has no type, it is inferred from the file extension. The second returned
+// value maps the path of every file read, relative to the given directory, to the sha256 of its content.
+func ExpandFiles(values []Values, directory string) ([]Values, map[string]string, error) {
+	expanded := make([]Values, 0, len(values))
+	inputs := make(map[string]string)
+	for index, value := range values {
+		if value.File == "" {
+			expanded = append(expanded, value)
+			continue
+		}
+		pattern := value.File
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(directory, pattern)
+		}
+		matches, err := filepathx.Glob(pattern)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s values layer: invalid file pattern %s: %s", humanize.Ordinal(index+1), value.File, err)
+		}
+		if len(matches) == 0 {
+			return nil, nil, fmt.Errorf("%s values layer: no file matches %s", humanize.Ordinal(index+1), value.File)
+		}
+		sort.Strings(matches)
+		for _, match := range matches {
+			if info, err := os.Stat(match); err == nil && info.IsDir() {
+				continue
+			}
+			layer := value
+			layer.File = match
+			if layer.Type == "" {
+				name := match
+				if layer.Encrypted && encryptedExtensions[strings.ToLower(filepath.Ext(name))] {
+					name = strings.TrimSuffix(name, filepath.Ext(name))
+				}
+				format, ok := extensionFormats[strings.ToLower(filepath.Ext(name))]
+				if !ok {
+					return nil, nil, fmt.Errorf("%s values layer: unable to infer the type of %s from its extension, a type must be set", humanize.Ordinal(index+1), match)
+				}
+				layer.Type = string(format)
+			}
+			if layer.Data, err = os.ReadFile(match); err != nil {
+				return nil, nil, fmt.Errorf("%s values layer: failed to read file: %s", humanize.Ordinal(index+1), err)
+			}
+			recordInput(inputs, directory, match, layer.Data)
+			expanded = append(expanded, layer)
+		}
+	}
+	return expanded, inputs, nil
+}
+
+// recordInput adds a file read to build the context to the given inputs, keyed by its path relative to the source
+// directory and holding the sha256 of its content.
+func recordInput(inputs map[string]string, directory, path string, content []byte) {
+	signature := sha256.Sum256(content)
+	relative, err := filepath.Rel(directory, path)
+	if err != nil {
+		relative = path
+	}
+	inputs[filepath.ToSlash(relative)] = hex.EncodeToString(signature[:])
+}
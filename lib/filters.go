@@ -2,71 +2,152 @@ package lib
 
 import (
 	"bytes"
+	"crypto/hmac"
 	"crypto/md5"
 	"crypto/sha1"
 	"crypto/sha256"
 	"crypto/sha512"
+	"encoding/base32"
 	"encoding/base64"
-	"encoding/csv"
+	"encoding/hex"
 	"fmt"
-	"io"
+	"hash/crc32"
+	"net/netip"
 	"os"
 	"path"
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"sort"
 	"strings"
+	"time"
 
 	"dario.cat/mergo"
 	"github.com/dustin/go-humanize"
+	"github.com/hashicorp/go-version"
 	json "github.com/json-iterator/go"
 	tfvars_parser "github.com/musukvl/tfvars-parser"
 	"github.com/nikolalohinski/gonja/v2/exec"
 	"github.com/pelletier/go-toml/v2"
 	"github.com/pkg/errors"
 	"github.com/yargevad/filepathx"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/sha3"
 	"golang.org/x/exp/slices"
 	"gopkg.in/yaml.v3"
 )
 
 var Filters = exec.NewFilterSet(map[string]exec.FilterFunction{
-	"abspath":    filterAbsPath,
-	"add":        filterAdd,
-	"append":     filterAppend,
-	"basename":   filterBasename,
-	"bool":       filterBool,
-	"concat":     filterConcat,
-	"dir":        filterDirname,
-	"dirname":    filterDirname,
-	"distinct":   filterDistinct,
-	"env":        filterEnv,
-	"fail":       filterFail,
-	"file":       filterFile,
-	"fileset":    filterFileSet,
-	"flatten":    filterFlatten,
-	"fromjson":   filterFromJSON,
-	"fromyaml":   filterFromYAML,
-	"fromtoml":   filterFromTOML,
-	"frombase64": filterFromBase64,
-	"fromcsv":    filterFromCSV,
-	"fromtfvars": filterFromTFVars,
-	"get":        filterGet,
-	"ifelse":     filterIfElse,
-	"insert":     filterInsert,
-	"keys":       filterKeys,
-	"match":      filterMatch,
-	"merge":      filterMerge,
-	"sha1":       filterSha1,
-	"sha256":     filterSha256,
-	"sha512":     filterSha512,
-	"md5":        filterMd5,
-	"split":      filterSplit,
-	"totoml":     filterToToml,
-	"toyaml":     filterToYAML,
-	"tobase64":   filterToBase64,
-	"try":        filterTry,
-	"unset":      filterUnset,
-	"values":     filterValues,
+	"abspath":              filterAbsPath,
+	"add":                  filterAdd,
+	"append":               filterAppend,
+	"argon2id":             filterArgon2id,
+	"basename":             filterBasename,
+	"bcrypt":               filterBcrypt,
+	"blake2b":              filterBlake2b,
+	"bool":                 filterBool,
+	"camel_case":           filterCamelCase,
+	"cidr_contains":        filterCIDRContains,
+	"cidr_merge":           filterCIDRMerge,
+	"cidrhost":             filterCIDRHost,
+	"cidrnetmask":          filterCIDRNetmask,
+	"cidrsubnet":           filterCIDRSubnet,
+	"cidrsubnets":          filterCIDRSubnets,
+	"concat":               filterConcat,
+	"constant_case":        filterConstantCase,
+	"crc32":                filterCRC32,
+	"delete_path":          filterDeletePath,
+	"diff":                 filterDiff,
+	"difference":           filterDifference,
+	"dir":                  filterDirname,
+	"dirname":              filterDirname,
+	"distinct":             filterDistinct,
+	"dns_label":            filterDNSLabel,
+	"env":                  filterEnv,
+	"fail":                 filterFail,
+	"file":                 filterFile,
+	"fileset":              filterFileSet,
+	"flatten":              filterFlatten,
+	"fromjson":             filterFromJSON,
+	"fromyaml":             filterFromYAML,
+	"fromtoml":             filterFromTOML,
+	"frombase64":           filterFromBase64,
+	"frombase32":           filterFromBase32,
+	"fromhex":              filterFromHex,
+	"fromcsv":              filterFromCSV,
+	"fromtfstate":          filterFromTFState,
+	"fromtfvars":           filterFromTFVars,
+	"fromini":              filterFromINI,
+	"fromdotenv":           filterFromDotEnv,
+	"fromproperties":       filterFromProperties,
+	"fromxml":              filterFromXML,
+	"get":                  filterGet,
+	"get_path":             filterGetPath,
+	"has_path":             filterHasPath,
+	"hmac":                 filterHMAC,
+	"ifelse":               filterIfElse,
+	"insert":               filterInsert,
+	"intersect":            filterIntersect,
+	"ip_in_range":          filterIPInRange,
+	"ip_version":           filterIPVersion,
+	"ipaddr":               filterIPAddr,
+	"jsonpatch":            filterJSONPatch,
+	"k8s_name":             filterK8sName,
+	"kebab_case":           filterKebabCase,
+	"keys":                 filterKeys,
+	"match":                filterMatch,
+	"merge":                filterMerge,
+	"mergepatch":           filterMergePatch,
+	"pascal_case":          filterPascalCase,
+	"query":                filterQuery,
+	"quote_hcl":            filterQuoteHCL,
+	"quote_json":           filterQuoteJSON,
+	"quote_powershell":     filterQuotePowerShell,
+	"quote_regex":          filterRegexEscape,
+	"quote_shell":          filterQuoteShell,
+	"quote_sql_ident":      filterQuoteSQLIdent,
+	"quote_yaml":           filterQuoteYAML,
+	"regex_escape":         filterRegexEscape,
+	"regex_findall":        filterRegexFindAll,
+	"regex_replace":        filterRegexReplace,
+	"regex_search":         filterRegexSearch,
+	"regex_split":          filterRegexSplit,
+	"set_path":             filterSetPath,
+	"sha1":                 filterSha1,
+	"sha256":               filterSha256,
+	"sha3_256":             filterSha3_256,
+	"sha3_512":             filterSha3_512,
+	"sha512":               filterSha512,
+	"md5":                  filterMd5,
+	"slugify":              filterSlugify,
+	"snake_case":           filterSnakeCase,
+	"split":                filterSplit,
+	"strftime":             filterStrftime,
+	"symmetric_difference": filterSymmetricDifference,
+	"timeadd":              filterTimeAdd,
+	"timecmp":              filterTimeCmp,
+	"todatetime":           filterToDatetime,
+	"toduration":           filterToDuration,
+	"toepoch":              filterToEpoch,
+	"totoml":               filterToToml,
+	"toyaml":               filterToYAML,
+	"tobase64":             filterToBase64,
+	"tobase32":             filterToBase32,
+	"tohex":                filterToHex,
+	"tocsv":                filterToCSV,
+	"toini":                filterToINI,
+	"todotenv":             filterToDotEnv,
+	"tohcl":                filterToHCL,
+	"totfvars":             filterToTFVars,
+	"toproperties":         filterToProperties,
+	"toxml":                filterToXML,
+	"try":                  filterTry,
+	"union":                filterUnion,
+	"unset":                filterUnset,
+	"values":               filterValues,
+	"version_compare":      filterVersionCompare,
+	"version_parts":        filterVersionParts,
+	"version_sort":         filterVersionSort,
 })
 
 func filterBool(e *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
@@ -166,6 +247,116 @@ func filterGet(e *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Va
 	return value
 }
 
+func filterGetPath(e *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	var (
+		path     string
+		strict   bool
+		fallback interface{}
+	)
+	if err := params.Take(
+		exec.PositionalArgument("path", nil, exec.StringArgument(&path)),
+		exec.KeywordArgument("strict", exec.AsValue(false), exec.BoolArgument(&strict)),
+		exec.KeywordArgument("default", exec.AsValue(nil), exec.AnyArgument(&fallback)),
+	); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	segments, err := parseAnyPath(path)
+	if err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	value, ok, err := lookupPath(in, segments)
+	if err != nil {
+		return exec.AsValue(err)
+	}
+	if !ok {
+		if fallback != nil {
+			return exec.AsValue(fallback)
+		}
+		if strict {
+			return exec.AsValue(fmt.Errorf("path '%s' not found in: %s", path, in.String()))
+		}
+		return exec.AsValue(nil)
+	}
+	return value
+}
+
+func filterHasPath(e *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	var (
+		path string
+	)
+	if err := params.Take(
+		exec.PositionalArgument("path", nil, exec.StringArgument(&path)),
+	); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	segments, err := parseAnyPath(path)
+	if err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	_, ok, err := lookupPath(in, segments)
+	if err != nil {
+		return exec.AsValue(err)
+	}
+	return exec.AsValue(ok)
+}
+
+func filterSetPath(e *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	var (
+		path  string
+		value interface{}
+	)
+	if err := params.Take(
+		exec.PositionalArgument("path", nil, exec.StringArgument(&path)),
+		exec.PositionalArgument("value", nil, exec.AnyArgument(&value)),
+	); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	segments, err := parseAnyPath(path)
+	if err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	out, err := replacePath(in, segments, value, false)
+	if err != nil {
+		return exec.AsValue(err)
+	}
+	return exec.AsValue(out)
+}
+
+func filterDeletePath(e *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	var (
+		path string
+	)
+	if err := params.Take(
+		exec.PositionalArgument("path", nil, exec.StringArgument(&path)),
+	); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	segments, err := parseAnyPath(path)
+	if err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if len(segments) == 0 {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("can not delete the whole input")))
+	}
+	out, err := replacePath(in, segments, nil, true)
+	if err != nil {
+		return exec.AsValue(err)
+	}
+	return exec.AsValue(out)
+}
+
 func filterValues(e *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
 	if in.IsError() {
 		return in
@@ -607,9 +798,9 @@ func filterMatch(e *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.
 	if !in.IsString() {
 		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
 	}
-	matcher, err := regexp.Compile(regex)
+	matcher, err := compileRegex(regex, regexFlags{})
 	if err != nil {
-		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("failed to compile: %s: %s", regex, err)))
+		return exec.AsValue(exec.ErrInvalidCall(err))
 	}
 
 	return exec.AsValue(matcher.MatchString(in.String()))
@@ -662,6 +853,50 @@ func filterDistinct(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *ex
 	return exec.AsValue(out)
 }
 
+func filterUnion(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	left, right, err := setOperands(in, params)
+	if err != nil {
+		return exec.AsValue(err)
+	}
+	return exec.AsValue(renderableValue(setUnion(left, right)))
+}
+
+func filterIntersect(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	left, right, err := setOperands(in, params)
+	if err != nil {
+		return exec.AsValue(err)
+	}
+	return exec.AsValue(renderableValue(setIntersection(left, right)))
+}
+
+func filterDifference(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	left, right, err := setOperands(in, params)
+	if err != nil {
+		return exec.AsValue(err)
+	}
+	return exec.AsValue(renderableValue(setDifference(left, right)))
+}
+
+func filterSymmetricDifference(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	left, right, err := setOperands(in, params)
+	if err != nil {
+		return exec.AsValue(err)
+	}
+	return exec.AsValue(renderableValue(setSymmetricDifference(left, right)))
+}
+
 func filterEnv(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
 	if in.IsError() {
 		return in
@@ -692,13 +927,26 @@ func filterFromBase64(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *
 	if in.IsError() {
 		return in
 	}
-	if err := params.Take(); err != nil {
+	var (
+		urlsafe bool
+	)
+	if err := params.Take(
+		exec.KeywordArgument("urlsafe", exec.AsValue(false), exec.BoolArgument(&urlsafe)),
+	); err != nil {
 		return exec.AsValue(exec.ErrInvalidCall(err))
 	}
 	if !in.IsString() {
 		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
 	}
-	decoded, err := base64.StdEncoding.DecodeString(in.String())
+	encoding, unpadded := base64.StdEncoding, base64.RawStdEncoding
+	if urlsafe {
+		encoding, unpadded = base64.URLEncoding, base64.RawURLEncoding
+	}
+	// Padding is optional, but must be correct when present
+	decoded, err := encoding.DecodeString(in.String())
+	if err != nil && !strings.Contains(in.String(), "=") {
+		decoded, err = unpadded.DecodeString(in.String())
+	}
 	if err != nil {
 		return exec.AsValue(fmt.Errorf("failed to decode '%s' from base64: %s", in.String(), err.Error()))
 	}
@@ -709,13 +957,27 @@ func filterToBase64(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *ex
 	if in.IsError() {
 		return in
 	}
-	if err := params.Take(); err != nil {
+	var (
+		urlsafe bool
+		padding bool
+	)
+	if err := params.Take(
+		exec.KeywordArgument("urlsafe", exec.AsValue(false), exec.BoolArgument(&urlsafe)),
+		exec.KeywordArgument("padding", exec.AsValue(true), exec.BoolArgument(&padding)),
+	); err != nil {
 		return exec.AsValue(exec.ErrInvalidCall(err))
 	}
 	if !in.IsString() {
 		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
 	}
-	encoded := base64.StdEncoding.EncodeToString([]byte(in.String()))
+	encoding := base64.StdEncoding
+	if urlsafe {
+		encoding = base64.URLEncoding
+	}
+	if !padding {
+		encoding = encoding.WithPadding(base64.NoPadding)
+	}
+	encoded := encoding.EncodeToString([]byte(in.String()))
 	return exec.AsValue(encoded)
 }
 
@@ -723,42 +985,56 @@ func filterFromCSV(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exe
 	if in.IsError() {
 		return in
 	}
-	if err := params.Take(); err != nil {
+	options := TabularOptions{}
+	if err := params.Take(
+		exec.KeywordArgument("delimiter", exec.AsValue(","), exec.StringArgument(&options.Delimiter)),
+		exec.KeywordArgument("header", exec.AsValue(true), exec.BoolArgument(&options.Header)),
+		exec.KeywordArgument("infer_types", exec.AsValue(false), exec.BoolArgument(&options.InferTypes)),
+		exec.KeywordArgument("index_by", exec.AsValue(""), exec.StringArgument(&options.IndexBy)),
+	); err != nil {
 		return exec.AsValue(exec.ErrInvalidCall(err))
 	}
 	if !in.IsString() {
 		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
 	}
 
-	r := strings.NewReader(in.String())
-	cr := csv.NewReader(r)
-
-	// Read the header row first, since that'll tell us which indices
-	// map to which attribute names.
-	headers, err := cr.Read()
+	rows, err := decodeDelimited([]byte(in.String()), ',', options)
 	if err != nil {
-		return exec.AsValue(fmt.Errorf("failed to read CSV header row: %s", err))
+		return exec.AsValue(fmt.Errorf("failed to parse CSV: %s", err))
 	}
 
-	var rows []interface{}
-	for {
-		cols, err := cr.Read()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return exec.AsValue(fmt.Errorf("failed to read CSV row: %s", err))
-		}
+	return exec.AsValue(rows)
+}
 
-		row := make(map[string]string, len(cols))
-		for index, value := range cols {
-			name := headers[index]
-			row[name] = value
-		}
-		rows = append(rows, row)
+func filterToCSV(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	var (
+		columns []string
+		options TabularOptions
+	)
+	if err := params.Take(
+		exec.KeywordArgument("columns", exec.AsValue([]string{}), exec.StringListArgument(&columns)),
+		exec.KeywordArgument("delimiter", exec.AsValue(","), exec.StringArgument(&options.Delimiter)),
+		exec.KeywordArgument("header", exec.AsValue(true), exec.BoolArgument(&options.Header)),
+	); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsList() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a list", in.String())))
+	}
+	casted := in.ToGoSimpleType(false)
+	if err, ok := casted.(error); ok {
+		return exec.AsValue(err)
 	}
 
-	return exec.AsValue(rows)
+	out, err := encodeDelimited(casted.([]interface{}), columns, ',', options)
+	if err != nil {
+		return exec.AsValue(fmt.Errorf("unable to marshal to CSV: %s", err))
+	}
+
+	return exec.AsValue(out)
 }
 
 func filterFromTFVars(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
@@ -784,6 +1060,101 @@ func filterFromTFVars(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *
 	return exec.AsValue(vars)
 }
 
+func filterToTFVars(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	if err := params.Take(); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsDict() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a dict", in.String())))
+	}
+	casted := in.ToGoSimpleType(false)
+	if err, ok := casted.(error); ok {
+		return exec.AsValue(err)
+	}
+	out, err := encodeTFVars(casted.(map[string]interface{}))
+	if err != nil {
+		return exec.AsValue(fmt.Errorf("unable to marshal to tfvars: %s", err))
+	}
+	return exec.AsValue(out)
+}
+
+func filterToHCL(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	var (
+		block          string
+		labels, blocks interface{}
+	)
+	if err := params.Take(
+		exec.KeywordArgument("block", exec.AsValue(""), exec.StringArgument(&block)),
+		exec.KeywordArgument("labels", exec.AsValue([]interface{}{}), exec.AnyArgument(&labels)),
+		exec.KeywordArgument("blocks", exec.AsValue([]interface{}{}), exec.AnyArgument(&blocks)),
+	); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	labelNames, ok := toStringList(labels)
+	if !ok {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("labels %s is not a list of strings", exec.AsValue(labels).String())))
+	}
+	blockNames, ok := toStringList(blocks)
+	if !ok {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("blocks %s is not a list of strings", exec.AsValue(blocks).String())))
+	}
+	if block == "" && (len(labelNames) > 0 || len(blockNames) > 0) {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("labels and blocks can only be set along with block")))
+	}
+	if in.IsNil() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is undefined", in.String())))
+	}
+	if block != "" && !in.IsDict() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a dict", in.String())))
+	}
+	casted := in.ToGoSimpleType(false)
+	if err, ok := casted.(error); ok {
+		return exec.AsValue(err)
+	}
+
+	var (
+		out string
+		err error
+	)
+	if block == "" {
+		out, err = encodeHCLExpression(casted)
+	} else {
+		out, err = encodeHCLBlock(casted.(map[string]interface{}), block, labelNames, blockNames)
+	}
+	if err != nil {
+		return exec.AsValue(fmt.Errorf("unable to marshal to hcl: %s", err))
+	}
+	return exec.AsValue(out)
+}
+
+func filterFromTFState(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	var allowSensitive bool
+	if err := params.Take(
+		exec.KeywordArgument("allow_sensitive", exec.AsValue(false), exec.BoolArgument(&allowSensitive)),
+	); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+
+	state, err := decodeTFState([]byte(in.String()), allowSensitive)
+	if err != nil {
+		return exec.AsValue(fmt.Errorf("failed to parse terraform state: %s", err))
+	}
+
+	return exec.AsValue(state)
+}
+
 func filterSha1(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
 	if in.IsError() {
 		return in
@@ -879,3 +1250,1366 @@ func filterMerge(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.
 
 	return exec.AsValue(inputSimpleType)
 }
+
+func filterFromINI(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	if err := params.Take(); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+	values, err := decodeINI([]byte(in.String()))
+	if err != nil {
+		return exec.AsValue(fmt.Errorf("failed to parse '%s' as INI: %s", in.String(), err))
+	}
+	return exec.AsValue(values)
+}
+
+func filterToINI(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	if err := params.Take(); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsDict() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a dict", in.String())))
+	}
+	casted := in.ToGoSimpleType(false)
+	if err, ok := casted.(error); ok {
+		return exec.AsValue(err)
+	}
+	out, err := encodeINI(casted.(map[string]interface{}))
+	if err != nil {
+		return exec.AsValue(fmt.Errorf("unable to marshal to INI: %s", err))
+	}
+	return exec.AsValue(out)
+}
+
+func filterFromDotEnv(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	if err := params.Take(); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+	values, err := decodeDotEnv([]byte(in.String()))
+	if err != nil {
+		return exec.AsValue(fmt.Errorf("failed to parse '%s' as dotenv: %s", in.String(), err))
+	}
+	return exec.AsValue(values)
+}
+
+func filterToDotEnv(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	if err := params.Take(); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsDict() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a dict", in.String())))
+	}
+	casted := in.ToGoSimpleType(false)
+	if err, ok := casted.(error); ok {
+		return exec.AsValue(err)
+	}
+	out, err := encodeDotEnv(casted.(map[string]interface{}))
+	if err != nil {
+		return exec.AsValue(fmt.Errorf("unable to marshal to dotenv: %s", err))
+	}
+	return exec.AsValue(out)
+}
+
+func filterFromProperties(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	if err := params.Take(); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+	values, err := decodeProperties([]byte(in.String()))
+	if err != nil {
+		return exec.AsValue(fmt.Errorf("failed to parse '%s' as properties: %s", in.String(), err))
+	}
+	return exec.AsValue(values)
+}
+
+func filterToProperties(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	if err := params.Take(); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsDict() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a dict", in.String())))
+	}
+	casted := in.ToGoSimpleType(false)
+	if err, ok := casted.(error); ok {
+		return exec.AsValue(err)
+	}
+	out, err := encodeProperties(casted.(map[string]interface{}))
+	if err != nil {
+		return exec.AsValue(fmt.Errorf("unable to marshal to properties: %s", err))
+	}
+	return exec.AsValue(out)
+}
+
+func filterFromXML(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	var (
+		attributePrefix string
+		textKey         string
+	)
+	if err := params.Take(
+		exec.KeywordArgument("attribute_prefix", exec.AsValue(defaultXMLAttributePrefix), exec.StringArgument(&attributePrefix)),
+		exec.KeywordArgument("text_key", exec.AsValue(defaultXMLTextKey), exec.StringArgument(&textKey)),
+	); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+	values, err := decodeXML([]byte(in.String()), xmlConvention{
+		AttributePrefix: attributePrefix,
+		TextKey:         textKey,
+	})
+	if err != nil {
+		return exec.AsValue(fmt.Errorf("failed to parse '%s' as XML: %s", in.String(), err))
+	}
+	return exec.AsValue(values)
+}
+
+func filterToXML(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	var (
+		root            string
+		attributePrefix string
+		textKey         string
+		indent          int
+		declaration     bool
+	)
+	if err := params.Take(
+		exec.KeywordArgument("root", exec.AsValue(""), exec.StringArgument(&root)),
+		exec.KeywordArgument("attribute_prefix", exec.AsValue(defaultXMLAttributePrefix), exec.StringArgument(&attributePrefix)),
+		exec.KeywordArgument("text_key", exec.AsValue(defaultXMLTextKey), exec.StringArgument(&textKey)),
+		exec.KeywordArgument("indent", exec.AsValue(2), exec.IntArgument(&indent)),
+		exec.KeywordArgument("declaration", exec.AsValue(true), exec.BoolArgument(&declaration)),
+	); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsDict() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a dict", in.String())))
+	}
+	casted := in.ToGoSimpleType(false)
+	if err, ok := casted.(error); ok {
+		return exec.AsValue(err)
+	}
+	var content interface{} = casted
+	if root == "" {
+		values := casted.(map[string]interface{})
+		if len(values) != 1 {
+			return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s must hold exactly one key to be used as root element or the 'root' argument must be set", in.String())))
+		}
+		for key, value := range values {
+			root, content = key, value
+		}
+	}
+	encoder := xmlEncoder{
+		xmlConvention: xmlConvention{
+			AttributePrefix: attributePrefix,
+			TextKey:         textKey,
+		},
+		Indent:      indent,
+		Declaration: declaration,
+	}
+	out, err := encoder.encode(root, content)
+	if err != nil {
+		return exec.AsValue(fmt.Errorf("unable to marshal to XML: %s", err))
+	}
+	return exec.AsValue(out)
+}
+
+func filterQuery(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	var expression string
+	if err := params.Take(
+		exec.PositionalArgument("expression", nil, exec.StringArgument(&expression)),
+	); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	node, err := parseQuery(expression)
+	if err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	result, err := node.evaluateValue(in)
+	if err != nil {
+		return exec.AsValue(err)
+	}
+	return exec.AsValue(renderableValue(result))
+}
+
+func filterJSONPatch(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	var (
+		operations interface{}
+	)
+	if err := params.Take(
+		exec.PositionalArgument("operations", nil, exec.AnyArgument(&operations)),
+	); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	operationsValue := exec.AsValue(operations)
+	if !operationsValue.IsList() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a list", operationsValue.String())))
+	}
+	document := in.ToGoSimpleType(false)
+	if err, ok := document.(error); ok {
+		return exec.AsValue(err)
+	}
+	operationsList := operationsValue.ToGoSimpleType(false)
+	if err, ok := operationsList.(error); ok {
+		return exec.AsValue(err)
+	}
+	out, err := applyJSONPatch(document, operationsList.([]interface{}))
+	if err != nil {
+		return exec.AsValue(err)
+	}
+	return exec.AsValue(out)
+}
+
+func filterMergePatch(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	var (
+		patch interface{}
+	)
+	if err := params.Take(
+		exec.PositionalArgument("patch", nil, exec.AnyArgument(&patch)),
+	); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	target := in.ToGoSimpleType(false)
+	if err, ok := target.(error); ok {
+		return exec.AsValue(err)
+	}
+	patchSimpleType := exec.AsValue(patch).ToGoSimpleType(false)
+	if err, ok := patchSimpleType.(error); ok {
+		return exec.AsValue(err)
+	}
+	return exec.AsValue(applyMergePatch(target, patchSimpleType))
+}
+
+func filterDiff(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	var (
+		other interface{}
+	)
+	if err := params.Take(
+		exec.PositionalArgument("other", nil, exec.AnyArgument(&other)),
+	); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	from := in.ToGoSimpleType(false)
+	if err, ok := from.(error); ok {
+		return exec.AsValue(err)
+	}
+	to := exec.AsValue(other).ToGoSimpleType(false)
+	if err, ok := to.(error); ok {
+		return exec.AsValue(err)
+	}
+	return exec.AsValue(diffValues("", from, to))
+}
+
+func filterRegexReplace(e *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	var (
+		pattern     string
+		replacement string
+		count       int
+		flags       regexFlags
+	)
+	if err := params.Take(
+		exec.PositionalArgument("pattern", nil, exec.StringArgument(&pattern)),
+		exec.PositionalArgument("replacement", exec.AsValue(""), exec.StringArgument(&replacement)),
+		exec.KeywordArgument("count", exec.AsValue(0), exec.IntArgument(&count)),
+		exec.KeywordArgument("ignorecase", exec.AsValue(false), exec.BoolArgument(&flags.IgnoreCase)),
+		exec.KeywordArgument("multiline", exec.AsValue(false), exec.BoolArgument(&flags.Multiline)),
+	); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+	if count < 0 {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("count must be positive")))
+	}
+	matcher, err := compileRegex(pattern, flags)
+	if err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	input := in.String()
+	template := convertReplacement(replacement)
+	limit := -1
+	if count > 0 {
+		limit = count
+	}
+	output := []byte{}
+	last := 0
+	for _, indices := range matcher.FindAllStringSubmatchIndex(input, limit) {
+		output = append(output, input[last:indices[0]]...)
+		output = matcher.ExpandString(output, template, input, indices)
+		last = indices[1]
+	}
+	output = append(output, input[last:]...)
+
+	return exec.AsValue(string(output))
+}
+
+func filterRegexSearch(e *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	var (
+		pattern string
+		flags   regexFlags
+	)
+	if err := params.Take(
+		exec.PositionalArgument("pattern", nil, exec.StringArgument(&pattern)),
+		exec.KeywordArgument("ignorecase", exec.AsValue(false), exec.BoolArgument(&flags.IgnoreCase)),
+		exec.KeywordArgument("multiline", exec.AsValue(false), exec.BoolArgument(&flags.Multiline)),
+	); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+	matcher, err := compileRegex(pattern, flags)
+	if err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	indices := matcher.FindStringSubmatchIndex(in.String())
+	if indices == nil {
+		return exec.AsValue(nil)
+	}
+
+	return exec.AsValue(regexGroups(matcher, in.String(), indices))
+}
+
+func filterRegexFindAll(e *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	var (
+		pattern string
+		flags   regexFlags
+	)
+	if err := params.Take(
+		exec.PositionalArgument("pattern", nil, exec.StringArgument(&pattern)),
+		exec.KeywordArgument("ignorecase", exec.AsValue(false), exec.BoolArgument(&flags.IgnoreCase)),
+		exec.KeywordArgument("multiline", exec.AsValue(false), exec.BoolArgument(&flags.Multiline)),
+	); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+	matcher, err := compileRegex(pattern, flags)
+	if err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	output := make([]interface{}, 0)
+	for _, indices := range matcher.FindAllStringSubmatchIndex(in.String(), -1) {
+		output = append(output, regexGroups(matcher, in.String(), indices))
+	}
+
+	return exec.AsValue(output)
+}
+
+func filterRegexSplit(e *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	var (
+		pattern  string
+		maxsplit int
+		flags    regexFlags
+	)
+	if err := params.Take(
+		exec.PositionalArgument("pattern", nil, exec.StringArgument(&pattern)),
+		exec.KeywordArgument("maxsplit", exec.AsValue(0), exec.IntArgument(&maxsplit)),
+		exec.KeywordArgument("ignorecase", exec.AsValue(false), exec.BoolArgument(&flags.IgnoreCase)),
+		exec.KeywordArgument("multiline", exec.AsValue(false), exec.BoolArgument(&flags.Multiline)),
+	); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+	if maxsplit < 0 {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("maxsplit must be positive")))
+	}
+	matcher, err := compileRegex(pattern, flags)
+	if err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	limit := -1
+	if maxsplit > 0 {
+		limit = maxsplit + 1
+	}
+	output := make([]interface{}, 0)
+	for _, item := range matcher.Split(in.String(), limit) {
+		output = append(output, item)
+	}
+
+	return exec.AsValue(output)
+}
+
+func filterRegexEscape(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	if err := params.Take(); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+
+	return exec.AsValue(regexp.QuoteMeta(in.String()))
+}
+
+func filterQuoteShell(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	if err := params.Take(); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+
+	return exec.AsValue(quoteShell(in.String()))
+}
+
+func filterQuoteHCL(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	if err := params.Take(); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+
+	return exec.AsValue(quoteHCL(in.String()))
+}
+
+func filterQuoteJSON(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	if err := params.Take(); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+
+	return exec.AsValue(quoteJSON(in.String()))
+}
+
+func filterQuoteYAML(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	if err := params.Take(); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+
+	return exec.AsValue(quoteYAML(in.String()))
+}
+
+func filterQuoteSQLIdent(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	var dialect string
+	if err := params.Take(
+		exec.KeywordArgument("dialect", exec.AsValue("ansi"), exec.StringArgument(&dialect)),
+	); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+
+	quoted, err := quoteSQLIdentifier(in.String(), dialect)
+	if err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	return exec.AsValue(quoted)
+}
+
+func filterQuotePowerShell(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	if err := params.Take(); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+
+	return exec.AsValue(quotePowerShell(in.String()))
+}
+
+func filterSnakeCase(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	if err := params.Take(); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+
+	return exec.AsValue(snakeCase(in.String()))
+}
+
+func filterCamelCase(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	if err := params.Take(); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+
+	return exec.AsValue(camelCase(in.String()))
+}
+
+func filterPascalCase(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	if err := params.Take(); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+
+	return exec.AsValue(pascalCase(in.String()))
+}
+
+func filterKebabCase(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	if err := params.Take(); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+
+	return exec.AsValue(kebabCase(in.String()))
+}
+
+func filterConstantCase(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	if err := params.Take(); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+
+	return exec.AsValue(constantCase(in.String()))
+}
+
+func filterSlugify(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	var (
+		maxLength int
+		separator string
+	)
+	if err := params.Take(
+		exec.KeywordArgument("max_length", exec.AsValue(0), exec.IntArgument(&maxLength)),
+		exec.KeywordArgument("separator", exec.AsValue("-"), exec.StringArgument(&separator)),
+	); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+	if maxLength < 0 {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("max_length must not be negative")))
+	}
+
+	return exec.AsValue(slugify(in.String(), separator, maxLength))
+}
+
+func filterDNSLabel(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	var maxLength int
+	if err := params.Take(
+		exec.KeywordArgument("max_length", exec.AsValue(63), exec.IntArgument(&maxLength)),
+	); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+
+	label, err := dnsLabel(in.String(), maxLength)
+	if err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	return exec.AsValue(label)
+}
+
+func filterK8sName(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	var maxLength int
+	if err := params.Take(
+		exec.KeywordArgument("max_length", exec.AsValue(253), exec.IntArgument(&maxLength)),
+	); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+
+	name, err := kubernetesName(in.String(), maxLength)
+	if err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	return exec.AsValue(name)
+}
+
+func filterCIDRSubnet(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	var (
+		newbits int
+		netnum  int
+	)
+	if err := params.Take(
+		exec.PositionalArgument("newbits", nil, exec.IntArgument(&newbits)),
+		exec.PositionalArgument("netnum", nil, exec.IntArgument(&netnum)),
+	); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+	prefix, err := parseCIDR(in.String())
+	if err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	subnet, err := cidrSubnet(prefix, newbits, netnum)
+	if err != nil {
+		return exec.AsValue(err)
+	}
+	return exec.AsValue(subnet)
+}
+
+func filterCIDRSubnets(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	if len(params.KwArgs) > 0 {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("keyword arguments are not supported")))
+	}
+	newbits := make([]int, 0, len(params.Args))
+	for index, argument := range params.Args {
+		if !argument.IsInteger() {
+			return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s argument %s is not an integer", humanize.Ordinal(index+1), argument.String())))
+		}
+		newbits = append(newbits, argument.Integer())
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+	prefix, err := parseCIDR(in.String())
+	if err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	subnets, err := cidrSubnets(prefix, newbits)
+	if err != nil {
+		return exec.AsValue(err)
+	}
+	return exec.AsValue(subnets)
+}
+
+func filterCIDRHost(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	var (
+		hostnum int
+	)
+	if err := params.Take(
+		exec.PositionalArgument("hostnum", nil, exec.IntArgument(&hostnum)),
+	); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+	prefix, err := parseCIDR(in.String())
+	if err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	host, err := cidrHost(prefix, hostnum)
+	if err != nil {
+		return exec.AsValue(err)
+	}
+	return exec.AsValue(host)
+}
+
+func filterCIDRNetmask(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	if err := params.Take(); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+	prefix, err := parseCIDR(in.String())
+	if err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	netmask, err := cidrNetmask(prefix)
+	if err != nil {
+		return exec.AsValue(err)
+	}
+	return exec.AsValue(netmask)
+}
+
+func filterCIDRContains(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	var (
+		other string
+	)
+	if err := params.Take(
+		exec.PositionalArgument("other", nil, exec.StringArgument(&other)),
+	); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+	outer, err := parseCIDR(in.String())
+	if err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	inner, err := parseNetwork(other)
+	if err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	return exec.AsValue(networkContains(outer, inner))
+}
+
+func filterCIDRMerge(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	if err := params.Take(); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsList() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a list", in.String())))
+	}
+	var err error
+	prefixes := []netip.Prefix{}
+	in.Iterate(func(idx, count int, item, _ *exec.Value) bool {
+		if !item.IsString() {
+			err = fmt.Errorf("%s is not a string", item.String())
+			return false
+		}
+		var prefix netip.Prefix
+		prefix, err = parseNetwork(item.String())
+		prefixes = append(prefixes, prefix)
+		return err == nil
+	}, func() {})
+	if err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	return exec.AsValue(mergeNetworks(prefixes))
+}
+
+func filterIPVersion(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	if err := params.Take(); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+	prefix, err := parseNetwork(in.String())
+	if err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	version, _ := ipAddr(prefix, "version")
+	return exec.AsValue(version)
+}
+
+func filterIPInRange(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	var (
+		start string
+		end   string
+	)
+	if err := params.Take(
+		exec.PositionalArgument("start", nil, exec.StringArgument(&start)),
+		exec.PositionalArgument("end", nil, exec.StringArgument(&end)),
+	); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+	addresses := []netip.Addr{}
+	for _, value := range []string{in.String(), start, end} {
+		address, err := netip.ParseAddr(value)
+		if err != nil {
+			return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a valid IP address", value)))
+		}
+		addresses = append(addresses, address.WithZone(""))
+	}
+	if addresses[1].Is4() != addresses[2].Is4() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s and %s are not of the same IP version", start, end)))
+	}
+	return exec.AsValue(addresses[0].Is4() == addresses[1].Is4() && addresses[0].Compare(addresses[1]) >= 0 && addresses[0].Compare(addresses[2]) <= 0)
+}
+
+func filterIPAddr(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	var (
+		query string
+	)
+	if err := params.Take(
+		exec.PositionalArgument("query", exec.AsValue(""), exec.StringArgument(&query)),
+	); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+	prefix, err := parseNetwork(in.String())
+	if query == "" {
+		if err != nil {
+			return exec.AsValue(false)
+		}
+		return in
+	}
+	if err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	result, err := ipAddr(prefix, query)
+	if err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	return exec.AsValue(result)
+}
+
+func filterVersionCompare(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	var (
+		other    *exec.Value
+		operator string
+	)
+	if err := params.Take(
+		exec.PositionalArgument("other", nil, func(value *exec.Value) error {
+			other = value
+			return nil
+		}),
+		exec.PositionalArgument("operator", nil, exec.StringArgument(&operator)),
+	); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	left, err := parseVersion(in)
+	if err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	right, err := parseVersion(other)
+	if err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	result, err := compareVersions(left, right, operator)
+	if err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	return exec.AsValue(result)
+}
+
+func filterVersionSort(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	var (
+		reverse bool
+	)
+	if err := params.Take(
+		exec.KeywordArgument("reverse", exec.AsValue(false), exec.BoolArgument(&reverse)),
+	); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsList() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a list", in.String())))
+	}
+	var err error
+	items := []interface{}{}
+	versions := []*version.Version{}
+	in.Iterate(func(idx, count int, item, _ *exec.Value) bool {
+		var parsed *version.Version
+		if parsed, err = parseVersion(item); err != nil {
+			return false
+		}
+		items = append(items, item.Interface())
+		versions = append(versions, parsed)
+		return true
+	}, func() {})
+	if err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	indices := make([]int, len(items))
+	for index := range indices {
+		indices[index] = index
+	}
+	sort.SliceStable(indices, func(i, j int) bool {
+		if reverse {
+			return versions[indices[i]].GreaterThan(versions[indices[j]])
+		}
+		return versions[indices[i]].LessThan(versions[indices[j]])
+	})
+	out := make([]interface{}, 0, len(items))
+	for _, index := range indices {
+		out = append(out, items[index])
+	}
+	return exec.AsValue(out)
+}
+
+func filterVersionParts(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	if err := params.Take(); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	parsed, err := parseVersion(in)
+	if err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	return exec.AsValue(versionParts(parsed))
+}
+
+func filterStrftime(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	var (
+		format string
+	)
+	if err := params.Take(
+		exec.PositionalArgument("format", nil, exec.StringArgument(&format)),
+	); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	timestamp, err := parseTimestamp(in)
+	if err != nil {
+		return exec.AsValue(err)
+	}
+	formatted, err := strftime(timestamp, format)
+	if err != nil {
+		return exec.AsValue(err)
+	}
+	return exec.AsValue(formatted)
+}
+
+func filterToDatetime(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	var (
+		format string
+	)
+	if err := params.Take(
+		exec.KeywordArgument("format", exec.AsValue(""), exec.StringArgument(&format)),
+	); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if format == "" {
+		timestamp, err := parseTimestamp(in)
+		if err != nil {
+			return exec.AsValue(err)
+		}
+		return exec.AsValue(formatTimestamp(timestamp))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+	timestamp, err := strptime(in.String(), format)
+	if err != nil {
+		return exec.AsValue(err)
+	}
+	return exec.AsValue(formatTimestamp(timestamp))
+}
+
+func filterToEpoch(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	if err := params.Take(); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	timestamp, err := parseTimestamp(in)
+	if err != nil {
+		return exec.AsValue(err)
+	}
+	return exec.AsValue(int(timestamp.Unix()))
+}
+
+func filterTimeAdd(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	var (
+		duration string
+	)
+	if err := params.Take(
+		exec.PositionalArgument("duration", nil, exec.StringArgument(&duration)),
+	); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	timestamp, err := parseTimestamp(in)
+	if err != nil {
+		return exec.AsValue(err)
+	}
+	parsed, err := parseDuration(duration)
+	if err != nil {
+		return exec.AsValue(err)
+	}
+	return exec.AsValue(formatTimestamp(timestamp.Add(parsed)))
+}
+
+func filterTimeCmp(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	var (
+		other *exec.Value
+	)
+	if err := params.Take(
+		exec.PositionalArgument("other", nil, func(value *exec.Value) error {
+			other = value
+			return nil
+		}),
+	); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	left, err := parseTimestamp(in)
+	if err != nil {
+		return exec.AsValue(err)
+	}
+	right, err := parseTimestamp(other)
+	if err != nil {
+		return exec.AsValue(err)
+	}
+	switch {
+	case left.Before(right):
+		return exec.AsValue(-1)
+	case left.After(right):
+		return exec.AsValue(1)
+	default:
+		return exec.AsValue(0)
+	}
+}
+
+func filterToDuration(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	if err := params.Take(); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+	duration, err := parseDuration(in.String())
+	if err != nil {
+		return exec.AsValue(err)
+	}
+	if duration%time.Second == 0 {
+		return exec.AsValue(int(duration / time.Second))
+	}
+	return exec.AsValue(duration.Seconds())
+}
+
+func filterToBase32(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	if err := params.Take(); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+	return exec.AsValue(base32.StdEncoding.EncodeToString([]byte(in.String())))
+}
+
+func filterFromBase32(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	if err := params.Take(); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+	// Padding is optional, but must be correct when present. The decoder ignores extra padding characters, so
+	// that the length of the input is checked as well
+	decoded, err := base32.StdEncoding.DecodeString(in.String())
+	if err == nil && len(in.String())%8 != 0 {
+		err = fmt.Errorf("illegal base32 data length %d", len(in.String()))
+	}
+	if err != nil && !strings.Contains(in.String(), "=") {
+		decoded, err = base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(in.String())
+	}
+	if err != nil {
+		return exec.AsValue(fmt.Errorf("failed to decode '%s' from base32: %s", in.String(), err.Error()))
+	}
+	return exec.AsValue(string(decoded))
+}
+
+func filterToHex(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	if err := params.Take(); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+	return exec.AsValue(hex.EncodeToString([]byte(in.String())))
+}
+
+func filterFromHex(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	if err := params.Take(); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+	decoded, err := hex.DecodeString(in.String())
+	if err != nil {
+		return exec.AsValue(fmt.Errorf("failed to decode '%s' from hex: %s", in.String(), err.Error()))
+	}
+	return exec.AsValue(string(decoded))
+}
+
+func filterSha3_256(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	if err := params.Take(); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+	return exec.AsValue(fmt.Sprintf("%x", sha3.Sum256([]byte(in.String()))))
+}
+
+func filterSha3_512(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	if err := params.Take(); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+	return exec.AsValue(fmt.Sprintf("%x", sha3.Sum512([]byte(in.String()))))
+}
+
+func filterBlake2b(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	var (
+		size int
+		key  string
+	)
+	if err := params.Take(
+		exec.KeywordArgument("digest_size", exec.AsValue(blake2b.Size), exec.IntArgument(&size)),
+		exec.KeywordArgument("key", exec.AsValue(""), exec.StringArgument(&key)),
+	); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+	if size < 1 || size > blake2b.Size {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("digest_size must be between 1 and %d", blake2b.Size)))
+	}
+	hasher, err := blake2b.New(size, []byte(key))
+	if err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	hasher.Write([]byte(in.String()))
+	return exec.AsValue(hex.EncodeToString(hasher.Sum(nil)))
+}
+
+func filterHMAC(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	var (
+		key       string
+		algorithm string
+	)
+	if err := params.Take(
+		exec.PositionalArgument("key", nil, exec.StringArgument(&key)),
+		exec.PositionalArgument("algorithm", exec.AsValue("sha256"), exec.StringArgument(&algorithm)),
+	); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+	constructor, err := hashConstructor(algorithm)
+	if err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	mac := hmac.New(constructor, []byte(key))
+	mac.Write([]byte(in.String()))
+	return exec.AsValue(hex.EncodeToString(mac.Sum(nil)))
+}
+
+func filterCRC32(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	if err := params.Take(); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+	return exec.AsValue(fmt.Sprintf("%08x", crc32.ChecksumIEEE([]byte(in.String()))))
+}
+
+func filterBcrypt(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	var (
+		cost int
+		salt string
+	)
+	if err := params.Take(
+		exec.KeywordArgument("cost", exec.AsValue(10), exec.IntArgument(&cost)),
+		exec.KeywordArgument("salt", exec.AsValue(""), exec.StringArgument(&salt)),
+	); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+	hashed, err := bcryptHash(in.String(), cost, salt)
+	if err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	return exec.AsValue(hashed)
+}
+
+func filterArgon2id(_ *exec.Evaluator, in *exec.Value, params *exec.VarArgs) *exec.Value {
+	if in.IsError() {
+		return in
+	}
+	var (
+		salt                                string
+		iterations, memory, threads, length int
+	)
+	if err := params.Take(
+		exec.KeywordArgument("salt", exec.AsValue(""), exec.StringArgument(&salt)),
+		exec.KeywordArgument("time", exec.AsValue(1), exec.IntArgument(&iterations)),
+		exec.KeywordArgument("memory", exec.AsValue(64*1024), exec.IntArgument(&memory)),
+		exec.KeywordArgument("threads", exec.AsValue(4), exec.IntArgument(&threads)),
+		exec.KeywordArgument("length", exec.AsValue(32), exec.IntArgument(&length)),
+	); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if !in.IsString() {
+		return exec.AsValue(exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String())))
+	}
+	hashed, err := argon2idHash(in.String(), salt, iterations, memory, threads, length)
+	if err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	return exec.AsValue(hashed)
+}
@@ -0,0 +1,109 @@
+package lib
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nikolalohinski/gonja/v2/exec"
+)
+
+// scalarToString serializes a scalar value for flat key/value formats such as INI, dotenv or properties files.
+func scalarToString(value interface{}) (string, error) {
+	switch typed := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return typed, nil
+	case bool:
+		return strconv.FormatBool(typed), nil
+	case int:
+		return strconv.Itoa(typed), nil
+	case int64:
+		return strconv.FormatInt(typed, 10), nil
+	case uint64:
+		return strconv.FormatUint(typed, 10), nil
+	case float64:
+		return strconv.FormatFloat(typed, 'f', -1, 64), nil
+	default:
+		return "", fmt.Errorf("%v is not a scalar value", value)
+	}
+}
+
+// sortedKeys returns the keys of a map in lexicographic order for deterministic outputs.
+func sortedKeys(values map[string]interface{}) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// toStringList returns the elements of a list of strings, and false if the value is not one.
+func toStringList(value interface{}) ([]string, bool) {
+	list, ok := exec.AsValue(value).ToGoSimpleType(false).([]interface{})
+	if !ok {
+		return nil, false
+	}
+	elements := make([]string, 0, len(list))
+	for _, element := range list {
+		typed, ok := element.(string)
+		if !ok {
+			return nil, false
+		}
+		elements = append(elements, typed)
+	}
+	return elements, true
+}
+
+// renderableValue replaces the nil elements of the lists held by a value with a nil *exec.Value, which the
+// engine prints and converts back to None where a bare nil element makes it panic.
+func renderableValue(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case []interface{}:
+		renderable := make([]interface{}, len(typed))
+		for index, item := range typed {
+			if item == nil {
+				renderable[index] = exec.AsValue(nil)
+				continue
+			}
+			renderable[index] = renderableValue(item)
+		}
+		return renderable
+	case map[string]interface{}:
+		renderable := make(map[string]interface{}, len(typed))
+		for key, item := range typed {
+			renderable[key] = renderableValue(item)
+		}
+		return renderable
+	default:
+		return value
+	}
+}
+
+// doubleQuotedEscapes maps the escape sequences supported within double quoted values to their unescaped byte.
+var doubleQuotedEscapes = map[byte]byte{
+	'\\': '\\',
+	'"':  '"',
+	'\'': '\'',
+	'$':  '$',
+	'`':  '`',
+	'n':  '\n',
+	'r':  '\r',
+	't':  '\t',
+}
+
+var doubleQuotedReplacer = strings.NewReplacer(
+	"\\", "\\\\",
+	"\"", "\\\"",
+	"\n", "\\n",
+	"\r", "\\r",
+	"\t", "\\t",
+)
+
+// quoteDoubleQuoted wraps a string within double quotes while escaping what needs to be.
+func quoteDoubleQuoted(value string) string {
+	return "\"" + doubleQuotedReplacer.Replace(value) + "\""
+}
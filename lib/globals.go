@@ -34,6 +34,8 @@ var Globals = exec.NewContext(map[string]interface{}{
 	"fileset":  fileSetGlobal,
 	"dirname":  dirnameGlobal,
 	"basename": basenameGlobal,
+	"query":    queryGlobal,
+	"now":      nowGlobal,
 })
 
 func absPathGlobal(e *exec.Evaluator, params *exec.VarArgs) *exec.Value {
@@ -161,3 +163,56 @@ func basenameGlobal(e *exec.Evaluator, params *exec.VarArgs) *exec.Value {
 	}
 	return exec.AsValue(filepath.Base(path))
 }
+
+func queryGlobal(e *exec.Evaluator, params *exec.VarArgs) *exec.Value {
+	var (
+		data       *exec.Value
+		expression string
+	)
+	if err := params.Take(
+		exec.PositionalArgument("data", nil, func(value *exec.Value) error {
+			data = value
+			return nil
+		}),
+		exec.PositionalArgument("expression", nil, exec.StringArgument(&expression)),
+	); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	if data.IsError() {
+		return data
+	}
+	node, err := parseQuery(expression)
+	if err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	result, err := node.evaluateValue(data)
+	if err != nil {
+		return exec.AsValue(err)
+	}
+	return exec.AsValue(result)
+}
+
+func nowGlobal(e *exec.Evaluator, params *exec.VarArgs) *exec.Value {
+	var (
+		utc    bool
+		format string
+	)
+	if err := params.Take(
+		exec.KeywordArgument("utc", exec.AsValue(true), exec.BoolArgument(&utc)),
+		exec.KeywordArgument("format", exec.AsValue(""), exec.StringArgument(&format)),
+	); err != nil {
+		return exec.AsValue(exec.ErrInvalidCall(err))
+	}
+	now := currentTime(e).Local()
+	if utc {
+		now = now.UTC()
+	}
+	if format == "" {
+		return exec.AsValue(formatTimestamp(now))
+	}
+	formatted, err := strftime(now, format)
+	if err != nil {
+		return exec.AsValue(err)
+	}
+	return exec.AsValue(formatted)
+}
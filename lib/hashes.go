@@ -0,0 +1,131 @@
+package lib
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/blowfish"
+	"golang.org/x/crypto/sha3"
+)
+
+// hashAlgorithms lists the algorithms the hmac filter can be used with, by the names of the hashlib module of
+// Python.
+var hashAlgorithms = map[string]func() hash.Hash{
+	"md5":      md5.New,
+	"sha1":     sha1.New,
+	"sha224":   sha256.New224,
+	"sha256":   sha256.New,
+	"sha384":   sha512.New384,
+	"sha512":   sha512.New,
+	"sha3_224": sha3.New224,
+	"sha3_256": sha3.New256,
+	"sha3_384": sha3.New384,
+	"sha3_512": sha3.New512,
+}
+
+func hashConstructor(algorithm string) (func() hash.Hash, error) {
+	constructor, ok := hashAlgorithms[algorithm]
+	if !ok {
+		algorithms := make([]string, 0, len(hashAlgorithms))
+		for name := range hashAlgorithms {
+			algorithms = append(algorithms, name)
+		}
+		sort.Strings(algorithms)
+		return nil, fmt.Errorf("unsupported algorithm %s, expected one of %s", algorithm, strings.Join(algorithms, ", "))
+	}
+	return constructor, nil
+}
+
+const (
+	bcryptMinCost     = 4
+	bcryptMaxCost     = 31
+	bcryptSaltSize    = 16
+	bcryptMaxPassword = 72
+)
+
+// bcryptEncoding is the base64 alphabet used by bcrypt hashes, which is not the standard one.
+var bcryptEncoding = base64.NewEncoding("./ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789").WithPadding(base64.NoPadding)
+
+// bcryptHash hashes a password in the `$2a$` format of golang.org/x/crypto/bcrypt, which does not allow passing
+// the salt. The salt is made of 22 characters of the bcrypt base64 alphabet, and is random when empty.
+func bcryptHash(password string, cost int, salt string) (string, error) {
+	if len(password) > bcryptMaxPassword {
+		return "", fmt.Errorf("password length exceeds %d bytes", bcryptMaxPassword)
+	}
+	if cost < bcryptMinCost || cost > bcryptMaxCost {
+		return "", fmt.Errorf("cost %d is outside of the allowed range %d to %d", cost, bcryptMinCost, bcryptMaxCost)
+	}
+	rawSalt := make([]byte, bcryptSaltSize)
+	if salt == "" {
+		if _, err := rand.Read(rawSalt); err != nil {
+			return "", fmt.Errorf("failed to generate a salt: %s", err)
+		}
+	} else {
+		// The last character only holds 2 bits of the salt, the others being ignored as in other implementations
+		decoded, err := bcryptEncoding.DecodeString(salt)
+		if len(salt) != bcryptEncoding.EncodedLen(bcryptSaltSize) || err != nil {
+			return "", fmt.Errorf("salt %s is not made of %d characters among %s", salt, bcryptEncoding.EncodedLen(bcryptSaltSize), "./A-Za-z0-9")
+		}
+		rawSalt = decoded
+	}
+
+	// Bug compatibility with C implementations, which use the trailing NULL of the password during expansion
+	key := append([]byte(password), 0)
+	cipher, err := blowfish.NewSaltedCipher(key, rawSalt)
+	if err != nil {
+		return "", err
+	}
+	for round := uint64(0); round < 1<<uint(cost); round++ {
+		blowfish.ExpandKey(key, cipher)
+		blowfish.ExpandKey(rawSalt, cipher)
+	}
+	data := []byte("OrpheanBeholderScryDoubt")
+	for block := 0; block < len(data); block += 8 {
+		for iteration := 0; iteration < 64; iteration++ {
+			cipher.Encrypt(data[block:block+8], data[block:block+8])
+		}
+	}
+	// Bug compatibility with C implementations again, which only encode 23 of the 24 encrypted bytes
+	return fmt.Sprintf("$2a$%02d$%s%s", cost, bcryptEncoding.EncodeToString(rawSalt), bcryptEncoding.EncodeToString(data[:23])), nil
+}
+
+const argon2MinSaltSize = 8
+
+// argon2idHash hashes a password with Argon2id, in the PHC string format used by the reference implementation
+// and by libraries such as passlib. The salt is 16 random bytes when empty.
+func argon2idHash(password, salt string, iterations, memory, threads, length int) (string, error) {
+	rawSalt := []byte(salt)
+	if salt == "" {
+		rawSalt = make([]byte, 16)
+		if _, err := rand.Read(rawSalt); err != nil {
+			return "", fmt.Errorf("failed to generate a salt: %s", err)
+		}
+	}
+	switch {
+	case len(rawSalt) < argon2MinSaltSize:
+		return "", fmt.Errorf("salt must be at least %d bytes long", argon2MinSaltSize)
+	case iterations < 1:
+		return "", fmt.Errorf("time must be at least 1")
+	case threads < 1 || threads > 255:
+		return "", fmt.Errorf("threads must be between 1 and 255")
+	case memory < 8*threads:
+		return "", fmt.Errorf("memory must be at least 8 times the number of threads")
+	case length < 4:
+		return "", fmt.Errorf("length must be at least 4")
+	}
+	key := argon2.IDKey([]byte(password), rawSalt, uint32(iterations), uint32(memory), uint8(threads), uint32(length))
+	return fmt.Sprintf(
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, memory, iterations, threads,
+		base64.RawStdEncoding.EncodeToString(rawSalt), base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
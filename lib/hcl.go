@@ -0,0 +1,165 @@
+package lib
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// hclKeywords are the identifiers that are not read as attribute names when written bare as the keys of an
+// object, such as `for` which starts a for expression instead.
+var hclKeywords = map[string]bool{
+	"for":   true,
+	"if":    true,
+	"in":    true,
+	"true":  true,
+	"false": true,
+	"null":  true,
+}
+
+// toCtyValue converts a scalar as returned by ToGoSimpleType into the cty value hclwrite generates literals from.
+func toCtyValue(value interface{}) (cty.Value, error) {
+	switch typed := value.(type) {
+	case nil:
+		return cty.NullVal(cty.DynamicPseudoType), nil
+	case bool:
+		return cty.BoolVal(typed), nil
+	case string:
+		return cty.StringVal(typed), nil
+	case int:
+		return cty.NumberIntVal(int64(typed)), nil
+	case float64:
+		if math.IsNaN(typed) || math.IsInf(typed, 0) {
+			return cty.NilVal, fmt.Errorf("%v can not be represented in HCL", typed)
+		}
+		return cty.NumberFloatVal(typed), nil
+	default:
+		return cty.NilVal, fmt.Errorf("%v of type %T can not be represented in HCL", value, value)
+	}
+}
+
+// tokensForValue generates the tokens of an HCL expression from a value as returned by ToGoSimpleType. Dicts are
+// written as objects with sorted keys, quoted unless they are identifiers other than keywords, and lists as tuples
+// so that their elements can be of different types.
+func tokensForValue(value interface{}) (hclwrite.Tokens, error) {
+	switch typed := value.(type) {
+	case []interface{}:
+		elements := make([]hclwrite.Tokens, 0, len(typed))
+		for _, element := range typed {
+			tokens, err := tokensForValue(element)
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, tokens)
+		}
+		return hclwrite.TokensForTuple(elements), nil
+	case map[string]interface{}:
+		attributes := make([]hclwrite.ObjectAttrTokens, 0, len(typed))
+		for _, key := range sortedKeys(typed) {
+			tokens, err := tokensForValue(typed[key])
+			if err != nil {
+				return nil, err
+			}
+			name := hclwrite.TokensForIdentifier(key)
+			if !hclsyntax.ValidIdentifier(key) || hclKeywords[key] {
+				name = hclwrite.TokensForValue(cty.StringVal(key))
+			}
+			attributes = append(attributes, hclwrite.ObjectAttrTokens{Name: name, Value: tokens})
+		}
+		return hclwrite.TokensForObject(attributes), nil
+	default:
+		converted, err := toCtyValue(value)
+		if err != nil {
+			return nil, err
+		}
+		return hclwrite.TokensForValue(converted), nil
+	}
+}
+
+// encodeHCLExpression serializes a value as a formatted HCL expression.
+func encodeHCLExpression(value interface{}) (string, error) {
+	tokens, err := tokensForValue(value)
+	if err != nil {
+		return "", err
+	}
+	return string(hclwrite.Format(tokens.Bytes())), nil
+}
+
+// encodeHCLBlock serializes a dict as a block of the given type and labels. The entries of the dict are written
+// as attributes, except for the keys listed in blocks which are written as nested blocks, once for a dict and
+// once per element for a list of dicts.
+func encodeHCLBlock(values map[string]interface{}, blockType string, labels []string, blocks []string) (string, error) {
+	if !hclsyntax.ValidIdentifier(blockType) {
+		return "", fmt.Errorf("%s is not a valid block type", blockType)
+	}
+	nested := make(map[string]bool, len(blocks))
+	for _, name := range blocks {
+		nested[name] = true
+	}
+	file := hclwrite.NewEmptyFile()
+	if err := writeHCLBody(file.Body().AppendNewBlock(blockType, labels).Body(), values, nested); err != nil {
+		return "", err
+	}
+	return string(hclwrite.Format(file.Bytes())), nil
+}
+
+// encodeTFVars serializes a dict as the assignments of a variable definitions file, with sorted names.
+func encodeTFVars(values map[string]interface{}) (string, error) {
+	file := hclwrite.NewEmptyFile()
+	if err := writeHCLBody(file.Body(), values, nil); err != nil {
+		return "", err
+	}
+	return string(hclwrite.Format(file.Bytes())), nil
+}
+
+// writeHCLBody writes the attributes of a body with sorted names, followed by its nested blocks separated by
+// blank lines like terraform fmt lays them out.
+func writeHCLBody(body *hclwrite.Body, values map[string]interface{}, nested map[string]bool) error {
+	keys := sortedKeys(values)
+	written := false
+	for _, key := range keys {
+		if !hclsyntax.ValidIdentifier(key) {
+			return fmt.Errorf("%s is not a valid HCL identifier", key)
+		}
+		if nested[key] {
+			continue
+		}
+		tokens, err := tokensForValue(values[key])
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %s", key, err)
+		}
+		body.SetAttributeRaw(key, tokens)
+		written = true
+	}
+	for _, key := range keys {
+		if !nested[key] {
+			continue
+		}
+		var elements []interface{}
+		switch typed := values[key].(type) {
+		case map[string]interface{}:
+			elements = []interface{}{typed}
+		case []interface{}:
+			elements = typed
+		default:
+			return fmt.Errorf("block %s is neither a dict nor a list of dicts", key)
+		}
+		for _, element := range elements {
+			content, ok := element.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("block %s is neither a dict nor a list of dicts", key)
+			}
+			if written {
+				body.AppendNewline()
+			}
+			if err := writeHCLBody(body.AppendNewBlock(key, nil).Body(), content, nested); err != nil {
+				return err
+			}
+			written = true
+		}
+	}
+	return nil
+}
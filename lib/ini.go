@@ -0,0 +1,187 @@
+package lib
+
+import (
+	"fmt"
+	"strings"
+)
+
+// decodeINI parses INI formatted data into a map where each section is a nested map of strings. Keys
+// defined before the first section are kept at the root. Values can be double quoted to use escape
+// sequences, single quoted to be taken literally, or span several lines with continuation lines indented deeper
+// than their key, so that sections can be indented as a whole.
+func decodeINI(data []byte) (map[string]interface{}, error) {
+	root := make(map[string]interface{})
+	current := root
+	lastKey := ""
+	lastIndent := 0
+	for index, line := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		number := index + 1
+		trimmed := strings.TrimSpace(line)
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		switch {
+		case trimmed == "":
+			lastKey = ""
+		case strings.HasPrefix(trimmed, ";") || strings.HasPrefix(trimmed, "#"):
+			continue
+		case lastKey != "" && indent > lastIndent:
+			current[lastKey] = current[lastKey].(string) + "\n" + trimmed
+		case strings.HasPrefix(trimmed, "["):
+			if !strings.HasSuffix(trimmed, "]") {
+				return nil, fmt.Errorf("line %d: section header %s is not closed", number, trimmed)
+			}
+			name := strings.TrimSpace(trimmed[1 : len(trimmed)-1])
+			if name == "" {
+				return nil, fmt.Errorf("line %d: section name is empty", number)
+			}
+			section, ok := root[name]
+			if !ok {
+				section = make(map[string]interface{})
+				root[name] = section
+			}
+			if current, ok = section.(map[string]interface{}); !ok {
+				return nil, fmt.Errorf("line %d: section [%s] conflicts with a key of the same name", number, name)
+			}
+			lastKey = ""
+		default:
+			separator := strings.IndexAny(trimmed, "=:")
+			if separator < 0 {
+				return nil, fmt.Errorf("line %d: expected a 'key = value' pair but got: %s", number, trimmed)
+			}
+			key := strings.TrimSpace(trimmed[:separator])
+			if key == "" {
+				return nil, fmt.Errorf("line %d: key is empty", number)
+			}
+			if _, isSection := current[key].(map[string]interface{}); isSection {
+				return nil, fmt.Errorf("line %d: key %s conflicts with a section of the same name", number, key)
+			}
+			raw := strings.TrimSpace(trimmed[separator+1:])
+			value, quoted, err := decodeINIValue(raw)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s", number, err)
+			}
+			current[key] = value
+			lastKey = key
+			lastIndent = indent
+			if quoted {
+				lastKey = ""
+			}
+		}
+	}
+	return root, nil
+}
+
+func decodeINIValue(raw string) (string, bool, error) {
+	if raw == "" {
+		return "", false, nil
+	}
+	var (
+		value string
+		rest  string
+	)
+	switch raw[0] {
+	case '"':
+		builder := strings.Builder{}
+		closed := false
+		index := 1
+		for ; index < len(raw) && !closed; index++ {
+			switch raw[index] {
+			case '"':
+				closed = true
+			case '\\':
+				if index+1 >= len(raw) {
+					return "", true, fmt.Errorf("unterminated escape sequence in %s", raw)
+				}
+				index++
+				unescaped, ok := doubleQuotedEscapes[raw[index]]
+				if !ok {
+					return "", true, fmt.Errorf("invalid escape sequence \\%c in %s", raw[index], raw)
+				}
+				builder.WriteByte(unescaped)
+			default:
+				builder.WriteByte(raw[index])
+			}
+		}
+		if !closed {
+			return "", true, fmt.Errorf("unterminated double quoted value %s", raw)
+		}
+		value, rest = builder.String(), raw[index:]
+	case '\'':
+		end := strings.IndexByte(raw[1:], '\'')
+		if end < 0 {
+			return "", true, fmt.Errorf("unterminated single quoted value %s", raw)
+		}
+		value, rest = raw[1:end+1], raw[end+2:]
+	default:
+		for index := 1; index < len(raw); index++ {
+			if (raw[index] == ';' || raw[index] == '#') && (raw[index-1] == ' ' || raw[index-1] == '\t') {
+				return strings.TrimSpace(raw[:index]), false, nil
+			}
+		}
+		return raw, false, nil
+	}
+	rest = strings.TrimSpace(rest)
+	if rest != "" && rest[0] != ';' && rest[0] != '#' {
+		return "", true, fmt.Errorf("unexpected characters after quoted value: %s", rest)
+	}
+	return value, true, nil
+}
+
+// encodeINI serializes a map into INI formatted data. Scalar values at the root are written first
+// and every nested map is then written as a section, all with keys sorted lexicographically.
+func encodeINI(values map[string]interface{}) (string, error) {
+	builder := strings.Builder{}
+	sections := []string{}
+	for _, key := range sortedKeys(values) {
+		if _, ok := values[key].(map[string]interface{}); ok {
+			sections = append(sections, key)
+			continue
+		}
+		if err := writeINIPair(&builder, key, values[key]); err != nil {
+			return "", fmt.Errorf("failed to encode %s: %s", key, err)
+		}
+	}
+	for _, name := range sections {
+		if err := validateINIKey(name); err != nil {
+			return "", err
+		}
+		if strings.ContainsAny(name, "[]") {
+			return "", fmt.Errorf("section name %q can not contain brackets", name)
+		}
+		if builder.Len() > 0 {
+			builder.WriteString("\n")
+		}
+		builder.WriteString("[" + name + "]\n")
+		section := values[name].(map[string]interface{})
+		for _, key := range sortedKeys(section) {
+			if err := writeINIPair(&builder, key, section[key]); err != nil {
+				return "", fmt.Errorf("failed to encode %s.%s: %s", name, key, err)
+			}
+		}
+	}
+	return builder.String(), nil
+}
+
+func writeINIPair(builder *strings.Builder, key string, value interface{}) error {
+	if err := validateINIKey(key); err != nil {
+		return err
+	}
+	serialized, err := scalarToString(value)
+	if err != nil {
+		return err
+	}
+	if serialized != strings.TrimSpace(serialized) || strings.ContainsAny(serialized, "\"'\n\r\t;#\\") {
+		serialized = quoteDoubleQuoted(serialized)
+	}
+	builder.WriteString(key + " = " + serialized + "\n")
+	return nil
+}
+
+func validateINIKey(key string) error {
+	if strings.TrimSpace(key) == "" {
+		return fmt.Errorf("key %q is empty", key)
+	}
+	if key != strings.TrimSpace(key) || strings.ContainsAny(key, "=:\n\r") || strings.HasPrefix(key, ";") || strings.HasPrefix(key, "#") || strings.HasPrefix(key, "[") {
+		return fmt.Errorf("key %q can not be represented in INI", key)
+	}
+	return nil
+}
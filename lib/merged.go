@@ -0,0 +1,139 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+type mergedContextFormat string
+
+const (
+	MergedContextJSON       mergedContextFormat = "json"
+	MergedContextJSONPretty mergedContextFormat = "json_pretty"
+	MergedContextYAML       mergedContextFormat = "yaml"
+	MergedContextTOML       mergedContextFormat = "toml"
+)
+
+var (
+	SupportedMergedContextFormats = []string{
+		string(MergedContextJSON),
+		string(MergedContextJSONPretty),
+		string(MergedContextYAML),
+		string(MergedContextTOML),
+	}
+)
+
+// EncodeMergedContext serializes the merged values in the given format with sorted keys, after replacing the
+// values matching any of the redact patterns by a placeholder. Patterns are paths such as `db.password`, where
+// `*` matches any part of a single key or list index, as in `users[*].token`, and `**` matches any number of
+// nested keys, as in `**.password`.
+func EncodeMergedContext(values map[string]interface{}, format string, redact []string) (string, error) {
+	patterns := make([]*regexp.Regexp, 0, len(redact))
+	for _, pattern := range redact {
+		compiled, err := compileRedactPattern(pattern)
+		if err != nil {
+			return "", fmt.Errorf("invalid redact pattern %s: %s", pattern, err)
+		}
+		patterns = append(patterns, compiled)
+	}
+	var redacted interface{} = values
+	if len(patterns) > 0 && values != nil {
+		redacted = redactPaths(deepCopy(values), "", patterns)
+	}
+
+	switch mergedContextFormat(format) {
+	case "", MergedContextJSON:
+		output, err := json.Marshal(redacted)
+		if err != nil {
+			return "", err
+		}
+		return string(output), nil
+	case MergedContextJSONPretty:
+		output, err := json.MarshalIndent(redacted, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(output), nil
+	case MergedContextYAML:
+		output := bytes.NewBuffer(nil)
+		encoder := yaml.NewEncoder(output)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(redacted); err != nil {
+			return "", err
+		}
+		return output.String(), nil
+	case MergedContextTOML:
+		if values == nil {
+			return "", nil
+		}
+		output, err := toml.Marshal(redacted)
+		if err != nil {
+			return "", err
+		}
+		return string(output), nil
+	default:
+		return "", fmt.Errorf("unsupported format %s", format)
+	}
+}
+
+func compileRedactPattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("pattern is empty")
+	}
+	expression := strings.Builder{}
+	expression.WriteString("^")
+	for position := 0; position < len(pattern); position++ {
+		switch pattern[position] {
+		case '\\':
+			if position+1 >= len(pattern) {
+				return nil, fmt.Errorf("invalid escape sequence at position %d", position+1)
+			}
+			expression.WriteString(regexp.QuoteMeta(pattern[position : position+2]))
+			position++
+		case '*':
+			if strings.HasPrefix(pattern[position:], "**.") {
+				// Any number of keys, including none
+				expression.WriteString(`(.*\.)?`)
+				position += 2
+			} else if strings.HasPrefix(pattern[position:], "**") {
+				expression.WriteString(".*")
+				position++
+			} else {
+				expression.WriteString(`[^.\[\]]*`)
+			}
+		case '?':
+			expression.WriteString(`[^.\[\]]`)
+		default:
+			expression.WriteString(regexp.QuoteMeta(pattern[position : position+1]))
+		}
+	}
+	expression.WriteString("$")
+	return regexp.Compile(expression.String())
+}
+
+func redactPaths(value interface{}, path string, patterns []*regexp.Regexp) interface{} {
+	if path != "" {
+		for _, pattern := range patterns {
+			if pattern.MatchString(path) {
+				return sensitiveValuePlaceholder
+			}
+		}
+	}
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for key, item := range typed {
+			typed[key] = redactPaths(item, joinPath(path, key), patterns)
+		}
+	case []interface{}:
+		for index, item := range typed {
+			typed[index] = redactPaths(item, fmt.Sprintf("%s[%d]", path, index), patterns)
+		}
+	}
+	return value
+}
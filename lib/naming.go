@@ -0,0 +1,174 @@
+package lib
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// splitWords splits a string into words, on any character other than a letter or a digit and on case changes:
+// `HTTPServerURL2` is split into `HTTP`, `Server` and `URL2`. Digits belong to the word they follow.
+func splitWords(value string) []string {
+	words := []string{}
+	current := []rune{}
+	runes := []rune(value)
+	for index, character := range runes {
+		if !unicode.IsLetter(character) && !unicode.IsDigit(character) {
+			if len(current) > 0 {
+				words = append(words, string(current))
+				current = current[:0]
+			}
+			continue
+		}
+		if len(current) > 0 && unicode.IsUpper(character) {
+			previous := current[len(current)-1]
+			followedByLower := index+1 < len(runes) && unicode.IsLower(runes[index+1])
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) || (unicode.IsUpper(previous) && followedByLower) {
+				words = append(words, string(current))
+				current = current[:0]
+			}
+		}
+		current = append(current, character)
+	}
+	if len(current) > 0 {
+		words = append(words, string(current))
+	}
+	return words
+}
+
+// capitalize upper cases the first letter of a word and lower cases the others.
+func capitalize(word string) string {
+	runes := []rune(strings.ToLower(word))
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+func snakeCase(value string) string {
+	return strings.ToLower(strings.Join(splitWords(value), "_"))
+}
+
+func kebabCase(value string) string {
+	return strings.ToLower(strings.Join(splitWords(value), "-"))
+}
+
+func constantCase(value string) string {
+	return strings.ToUpper(strings.Join(splitWords(value), "_"))
+}
+
+func pascalCase(value string) string {
+	words := splitWords(value)
+	for index, word := range words {
+		words[index] = capitalize(word)
+	}
+	return strings.Join(words, "")
+}
+
+func camelCase(value string) string {
+	words := splitWords(value)
+	for index, word := range words {
+		if index == 0 {
+			words[index] = strings.ToLower(word)
+		} else {
+			words[index] = capitalize(word)
+		}
+	}
+	return strings.Join(words, "")
+}
+
+// transliterations maps the letters that do not decompose into an ASCII letter and combining marks to their
+// usual ASCII spelling.
+var transliterations = map[rune]string{
+	'ß': "ss", 'ẞ': "SS",
+	'æ': "ae", 'Æ': "AE",
+	'œ': "oe", 'Œ': "OE",
+	'ø': "o", 'Ø': "O",
+	'đ': "d", 'Đ': "D",
+	'ð': "d", 'Ð': "D",
+	'ł': "l", 'Ł': "L",
+	'þ': "th", 'Þ': "TH",
+	'ı': "i",
+}
+
+// transliterate replaces accented letters by their ASCII counterpart, such as `é` by `e` or `ß` by `ss`. Other
+// characters are left as is.
+func transliterate(value string) string {
+	builder := strings.Builder{}
+	for _, character := range norm.NFD.String(value) {
+		if unicode.Is(unicode.Mn, character) {
+			continue
+		}
+		if replacement, ok := transliterations[character]; ok {
+			builder.WriteString(replacement)
+			continue
+		}
+		builder.WriteRune(character)
+	}
+	return builder.String()
+}
+
+// slugify transliterates a string, lower cases it and replaces each run of characters other than ASCII letters
+// and digits by the separator. When maxLength is positive, the slug is truncated to that many bytes without
+// leaving a trailing separator.
+func slugify(value, separator string, maxLength int) string {
+	words := strings.FieldsFunc(strings.ToLower(transliterate(value)), func(character rune) bool {
+		return !(character >= 'a' && character <= 'z' || character >= '0' && character <= '9')
+	})
+	slug := strings.Join(words, separator)
+	if maxLength > 0 && len(slug) > maxLength {
+		slug = strings.TrimRight(slug[:maxLength], separator)
+	}
+	return slug
+}
+
+// hashSuffixLength is the number of hexadecimal characters of the hash appended to truncated names.
+const hashSuffixLength = 8
+
+// truncateWithHash truncates a name made of dash separated parts to maxLength, appending a short hash of the
+// original input so that distinct inputs sharing a long prefix keep distinct names.
+func truncateWithHash(name, input string, maxLength int) string {
+	if len(name) <= maxLength {
+		return name
+	}
+	sum := sha256.Sum256([]byte(input))
+	suffix := hex.EncodeToString(sum[:])[:hashSuffixLength]
+	prefix := strings.TrimRight(name[:maxLength-hashSuffixLength-1], "-.")
+	if prefix == "" {
+		return suffix
+	}
+	return prefix + "-" + suffix
+}
+
+// dnsLabel turns a string into an RFC 1123 label: up to maxLength lower case ASCII letters, digits and dashes,
+// starting and ending with a letter or a digit.
+func dnsLabel(value string, maxLength int) (string, error) {
+	if maxLength < hashSuffixLength+2 || maxLength > 63 {
+		return "", fmt.Errorf("max_length must be between %d and 63", hashSuffixLength+2)
+	}
+	label := slugify(value, "-", 0)
+	if label == "" {
+		return "", fmt.Errorf("%s holds no character allowed in a DNS label", value)
+	}
+	return truncateWithHash(label, value, maxLength), nil
+}
+
+// kubernetesName turns a string into an RFC 1123 subdomain as required for the names of most Kubernetes
+// objects: DNS labels separated by dots, up to maxLength characters long.
+func kubernetesName(value string, maxLength int) (string, error) {
+	if maxLength < hashSuffixLength+2 || maxLength > 253 {
+		return "", fmt.Errorf("max_length must be between %d and 253", hashSuffixLength+2)
+	}
+	labels := []string{}
+	for _, part := range strings.Split(value, ".") {
+		if label := slugify(part, "-", 0); label != "" {
+			labels = append(labels, label)
+		}
+	}
+	if len(labels) == 0 {
+		return "", fmt.Errorf("%s holds no character allowed in a Kubernetes name", value)
+	}
+	return truncateWithHash(strings.Join(labels, "."), value, maxLength), nil
+}
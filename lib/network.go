@@ -0,0 +1,250 @@
+package lib
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+	"sort"
+	"strings"
+)
+
+// This file holds the IP address computations of the network filters, which follow the semantics of the
+// cidrsubnet, cidrsubnets, cidrhost and cidrnetmask functions of Terraform so that templates compute the same
+// values as HCL does.
+
+// parseCIDR parses a network in CIDR notation such as `10.0.0.0/16`. Host bits may be set and are ignored.
+func parseCIDR(value string) (netip.Prefix, error) {
+	prefix, err := netip.ParsePrefix(value)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("%s is not a valid CIDR notation", value)
+	}
+	return prefix, nil
+}
+
+// parseNetwork parses either an address, which is handled as a network holding only this address, or a
+// network in CIDR notation. Unlike parseCIDR, the host bits are kept.
+func parseNetwork(value string) (netip.Prefix, error) {
+	if strings.Contains(value, "/") {
+		return parseCIDR(value)
+	}
+	address, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("%s is neither a valid IP address nor a valid CIDR notation", value)
+	}
+	address = address.WithZone("")
+	return netip.PrefixFrom(address, address.BitLen()), nil
+}
+
+func addressToInt(address netip.Addr) *big.Int {
+	return new(big.Int).SetBytes(address.AsSlice())
+}
+
+func intToAddress(value *big.Int, bits int) netip.Addr {
+	address, _ := netip.AddrFromSlice(value.FillBytes(make([]byte, bits/8)))
+	return address
+}
+
+// networkRange returns the first and last addresses of the network as integers.
+func networkRange(prefix netip.Prefix) (*big.Int, *big.Int) {
+	first := addressToInt(prefix.Masked().Addr())
+	size := new(big.Int).Lsh(big.NewInt(1), uint(prefix.Addr().BitLen()-prefix.Bits()))
+	return first, new(big.Int).Sub(new(big.Int).Add(first, size), big.NewInt(1))
+}
+
+func cidrSubnet(prefix netip.Prefix, newbits, netnum int) (string, error) {
+	bits := prefix.Addr().BitLen()
+	if newbits < 0 {
+		return "", fmt.Errorf("newbits must not be negative")
+	}
+	if prefix.Bits()+newbits > bits {
+		return "", fmt.Errorf("insufficient address space to extend prefix of %d by %d", prefix.Bits(), newbits)
+	}
+	if netnum < 0 || big.NewInt(int64(netnum)).Cmp(new(big.Int).Lsh(big.NewInt(1), uint(newbits))) >= 0 {
+		return "", fmt.Errorf("prefix extension of %d does not accommodate a subnet numbered %d", newbits, netnum)
+	}
+	first, _ := networkRange(prefix)
+	offset := new(big.Int).Lsh(big.NewInt(int64(netnum)), uint(bits-prefix.Bits()-newbits))
+	subnet := netip.PrefixFrom(intToAddress(first.Add(first, offset), bits), prefix.Bits()+newbits)
+	return subnet.String(), nil
+}
+
+// cidrSubnets allocates consecutive subnets of the network, each extending its prefix by the matching number of
+// bits. Each subnet starts at the first address aligned on its size after the previous subnet.
+func cidrSubnets(prefix netip.Prefix, newbits []int) ([]interface{}, error) {
+	bits := prefix.Addr().BitLen()
+	_, last := networkRange(prefix)
+	cursor, _ := networkRange(prefix)
+	subnets := make([]interface{}, 0, len(newbits))
+	for _, extension := range newbits {
+		if extension < 1 {
+			return nil, fmt.Errorf("must extend prefix by at least one bit")
+		}
+		length := prefix.Bits() + extension
+		if length > bits {
+			return nil, fmt.Errorf("would extend prefix to %d bits, which is too long for an IPv%d address", length, map[int]int{32: 4, 128: 6}[bits])
+		}
+		size := new(big.Int).Lsh(big.NewInt(1), uint(bits-length))
+		remainder := new(big.Int).Mod(cursor, size)
+		if remainder.Sign() > 0 {
+			cursor.Add(cursor, new(big.Int).Sub(size, remainder))
+		}
+		end := new(big.Int).Sub(new(big.Int).Add(cursor, size), big.NewInt(1))
+		if end.Cmp(last) > 0 {
+			return nil, fmt.Errorf("not enough remaining address space for a subnet with a prefix of %d bits after %s", length, subnets[len(subnets)-1])
+		}
+		subnets = append(subnets, netip.PrefixFrom(intToAddress(cursor, bits), length).String())
+		cursor = end.Add(end, big.NewInt(1))
+	}
+	return subnets, nil
+}
+
+func cidrHost(prefix netip.Prefix, hostnum int) (string, error) {
+	bits := prefix.Addr().BitLen()
+	first, last := networkRange(prefix)
+	size := new(big.Int).Lsh(big.NewInt(1), uint(bits-prefix.Bits()))
+	host := big.NewInt(int64(hostnum))
+	if hostnum < 0 {
+		host.Add(host, size)
+	}
+	host.Add(host, first)
+	if host.Cmp(first) < 0 || host.Cmp(last) > 0 {
+		return "", fmt.Errorf("prefix of %d does not accommodate a host numbered %d", prefix.Bits(), hostnum)
+	}
+	return intToAddress(host, bits).String(), nil
+}
+
+func cidrNetmask(prefix netip.Prefix) (string, error) {
+	if !prefix.Addr().Is4() {
+		return "", fmt.Errorf("only IPv4 networks are supported")
+	}
+	return prefixMask(prefix.Bits(), 32).String(), nil
+}
+
+func prefixMask(length, bits int) netip.Addr {
+	mask := new(big.Int).Lsh(big.NewInt(1), uint(bits))
+	mask.Sub(mask, new(big.Int).Lsh(big.NewInt(1), uint(bits-length)))
+	return intToAddress(mask, bits)
+}
+
+// networkContains returns whether all the addresses of the inner network belong to the outer network.
+func networkContains(outer, inner netip.Prefix) bool {
+	if outer.Addr().Is4() != inner.Addr().Is4() {
+		return false
+	}
+	outerFirst, outerLast := networkRange(outer)
+	innerFirst, innerLast := networkRange(inner)
+	return innerFirst.Cmp(outerFirst) >= 0 && innerLast.Cmp(outerLast) <= 0
+}
+
+var supportedIPAddrQueries = []string{
+	"address", "broadcast", "cidr", "first_usable", "host", "hostmask", "last_usable", "netmask", "network",
+	"prefix", "private", "public", "size", "version",
+}
+
+// ipAddr returns the requested property of an address or network, in the manner of the ipaddr filter of
+// Ansible.
+func ipAddr(prefix netip.Prefix, query string) (interface{}, error) {
+	bits := prefix.Addr().BitLen()
+	first, last := networkRange(prefix)
+	switch query {
+	case "address":
+		return prefix.Addr().String(), nil
+	case "host":
+		return prefix.String(), nil
+	case "cidr":
+		return prefix.Masked().String(), nil
+	case "network":
+		return intToAddress(first, bits).String(), nil
+	case "broadcast":
+		if !prefix.Addr().Is4() {
+			return nil, nil
+		}
+		return intToAddress(last, bits).String(), nil
+	case "netmask":
+		return prefixMask(prefix.Bits(), bits).String(), nil
+	case "hostmask":
+		return intToAddress(new(big.Int).Sub(last, first), bits).String(), nil
+	case "prefix":
+		return prefix.Bits(), nil
+	case "size":
+		size := new(big.Int).Add(new(big.Int).Sub(last, first), big.NewInt(1))
+		if size.IsInt64() {
+			return int(size.Int64()), nil
+		}
+		// Larger IPv6 networks would lose precision as floats
+		return size.String(), nil
+	case "first_usable", "last_usable":
+		if prefix.Addr().Is4() && prefix.Bits() < 31 {
+			first.Add(first, big.NewInt(1))
+			last.Sub(last, big.NewInt(1))
+		}
+		if query == "first_usable" {
+			return intToAddress(first, bits).String(), nil
+		}
+		return intToAddress(last, bits).String(), nil
+	case "version":
+		if prefix.Addr().Is4() {
+			return 4, nil
+		}
+		return 6, nil
+	case "private":
+		return prefix.Addr().IsPrivate(), nil
+	case "public":
+		return prefix.Addr().IsGlobalUnicast() && !prefix.Addr().IsPrivate(), nil
+	default:
+		return nil, fmt.Errorf("unsupported query %s, expected one of %s", query, strings.Join(supportedIPAddrQueries, ", "))
+	}
+}
+
+// mergeNetworks returns the smallest list of networks covering exactly the addresses of the given networks,
+// IPv4 networks being listed first.
+func mergeNetworks(prefixes []netip.Prefix) []interface{} {
+	type addressRange struct {
+		first, last *big.Int
+		bits        int
+	}
+	ranges := make([]addressRange, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		first, last := networkRange(prefix)
+		ranges = append(ranges, addressRange{first: first, last: last, bits: prefix.Addr().BitLen()})
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if ranges[i].bits != ranges[j].bits {
+			return ranges[i].bits < ranges[j].bits
+		}
+		return ranges[i].first.Cmp(ranges[j].first) < 0
+	})
+	merged := []addressRange{}
+	for _, current := range ranges {
+		if len(merged) > 0 {
+			previous := &merged[len(merged)-1]
+			next := new(big.Int).Add(previous.last, big.NewInt(1))
+			if previous.bits == current.bits && current.first.Cmp(next) <= 0 {
+				if current.last.Cmp(previous.last) > 0 {
+					previous.last = current.last
+				}
+				continue
+			}
+		}
+		merged = append(merged, current)
+	}
+	networks := make([]interface{}, 0, len(merged))
+	for _, current := range merged {
+		cursor := current.first
+		for cursor.Cmp(current.last) <= 0 {
+			// Take the largest block aligned on the cursor that does not go past the end of the range
+			length := current.bits
+			for length > 0 {
+				size := new(big.Int).Lsh(big.NewInt(1), uint(current.bits-length+1))
+				end := new(big.Int).Sub(new(big.Int).Add(cursor, size), big.NewInt(1))
+				if new(big.Int).Mod(cursor, size).Sign() != 0 || end.Cmp(current.last) > 0 {
+					break
+				}
+				length--
+			}
+			networks = append(networks, netip.PrefixFrom(intToAddress(cursor, current.bits), length).String())
+			cursor = new(big.Int).Add(cursor, new(big.Int).Lsh(big.NewInt(1), uint(current.bits-length)))
+		}
+	}
+	return networks
+}
@@ -0,0 +1,83 @@
+package lib
+
+import (
+	"fmt"
+	"strconv"
+
+	json "github.com/json-iterator/go"
+	"gopkg.in/yaml.v3"
+)
+
+type overrideType string
+
+const (
+	OverrideString overrideType = "string"
+	OverrideInt    overrideType = "int"
+	OverrideBool   overrideType = "bool"
+	OverrideJSON   overrideType = "json"
+)
+
+var (
+	SupportedOverrideTypes = []string{
+		string(OverrideString),
+		string(OverrideInt),
+		string(OverrideBool),
+		string(OverrideJSON),
+	}
+)
+
+// applyOverrides sets each override in order on top of the merged values, creating intermediate
+// dictionaries and lists when missing.
+func applyOverrides(values map[string]interface{}, overrides []Override) (map[string]interface{}, error) {
+	if len(overrides) == 0 {
+		return values, nil
+	}
+	if values == nil {
+		values = make(map[string]interface{})
+	}
+	for _, override := range overrides {
+		segments, err := parsePath(override.Path)
+		if err != nil {
+			return nil, err
+		}
+		value, err := override.decode()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode value of %s: %s", override.Path, err)
+		}
+		if _, err := setPath(values, segments, value); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+func (o Override) decode() (interface{}, error) {
+	switch overrideType(o.Type) {
+	case "", OverrideString:
+		return o.Value, nil
+	case OverrideInt:
+		value, err := strconv.Atoi(o.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an integer", o.Value)
+		}
+		return value, nil
+	case OverrideBool:
+		value, err := strconv.ParseBool(o.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a boolean", o.Value)
+		}
+		return value, nil
+	case OverrideJSON:
+		// Validate JSON format before unmarshalling with YAML decoder to avoid casting ints to floats
+		if err := json.Unmarshal([]byte(o.Value), new(interface{})); err != nil {
+			return nil, err
+		}
+		var value interface{}
+		if err := yaml.Unmarshal([]byte(o.Value), &value); err != nil {
+			return nil, err
+		}
+		return value, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %s", o.Type)
+	}
+}
@@ -0,0 +1,313 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// applyJSONPatch applies the operations of a JSON patch as specified in RFC 6902 to the document, which is
+// modified in place. Operations are applied in order and the first failing one stops the whole patch with an
+// error naming its index in the list of operations.
+func applyJSONPatch(document interface{}, operations []interface{}) (interface{}, error) {
+	for index, operation := range operations {
+		patched, err := applyJSONPatchOperation(document, operation)
+		if err != nil {
+			if name, ok := patchOperationName(operation); ok {
+				return nil, fmt.Errorf("failed to apply %s operation at index %d of the patch: %s", name, index, err)
+			}
+			return nil, fmt.Errorf("failed to apply operation at index %d of the patch: %s", index, err)
+		}
+		document = patched
+	}
+	return document, nil
+}
+
+func patchOperationName(operation interface{}) (string, bool) {
+	fields, ok := operation.(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	name, ok := fields["op"].(string)
+	return name, ok
+}
+
+func applyJSONPatchOperation(document interface{}, operation interface{}) (interface{}, error) {
+	fields, ok := operation.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s is not a dict", encodePatchValue(operation))
+	}
+	name, ok := fields["op"].(string)
+	if !ok {
+		return nil, fmt.Errorf("op is missing or is not a string")
+	}
+	path, err := patchOperationPointer(fields, "path")
+	if err != nil {
+		return nil, err
+	}
+	value, hasValue := fields["value"]
+	if !hasValue && (name == "add" || name == "replace" || name == "test") {
+		return nil, fmt.Errorf("value is missing for %s operation", name)
+	}
+
+	switch name {
+	case "add":
+		return patchPointer(document, path, func(parent interface{}, segment pathSegment) (interface{}, error) {
+			return addAtPointer(parent, path, segment, value)
+		})
+	case "remove":
+		return patchPointer(document, path, func(parent interface{}, segment pathSegment) (interface{}, error) {
+			return removeAtPointer(parent, path, segment)
+		})
+	case "replace":
+		return patchPointer(document, path, func(parent interface{}, segment pathSegment) (interface{}, error) {
+			parent, err := removeAtPointer(parent, path, segment)
+			if err != nil {
+				return nil, err
+			}
+			return addAtPointer(parent, path, segment, value)
+		})
+	case "move", "copy":
+		from, err := patchOperationPointer(fields, "from")
+		if err != nil {
+			return nil, err
+		}
+		moved, err := lookupPointer(document, from, from)
+		if err != nil {
+			return nil, err
+		}
+		if name == "copy" {
+			moved = deepCopy(moved)
+		} else {
+			if len(path) > len(from) && formatPath(path[:len(from)]) == formatPath(from) {
+				return nil, fmt.Errorf("can not move %s into one of its children %s", formatPath(from), formatPath(path))
+			}
+			document, err = patchPointer(document, from, func(parent interface{}, segment pathSegment) (interface{}, error) {
+				return removeAtPointer(parent, from, segment)
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		return patchPointer(document, path, func(parent interface{}, segment pathSegment) (interface{}, error) {
+			return addAtPointer(parent, path, segment, moved)
+		})
+	case "test":
+		current, err := lookupPointer(document, path, path)
+		if err != nil {
+			return nil, err
+		}
+		if !equalQueryValues(current, value) {
+			return nil, fmt.Errorf("%s holds %s instead of %s", formatPath(path), encodePatchValue(current), encodePatchValue(value))
+		}
+		return document, nil
+	default:
+		return nil, fmt.Errorf("unsupported op %s, expected one of add, remove, replace, move, copy or test", name)
+	}
+}
+
+func patchOperationPointer(fields map[string]interface{}, field string) ([]pathSegment, error) {
+	pointer, ok := fields[field].(string)
+	if !ok {
+		return nil, fmt.Errorf("%s is missing or is not a string", field)
+	}
+	return parsePointer(pointer)
+}
+
+// patchPointer calls the given function with the parent of the value the pointer refers to, and the last
+// segment of the pointer. It returns the document where the parent is replaced by the result of the function.
+func patchPointer(document interface{}, pointer []pathSegment, patch func(parent interface{}, segment pathSegment) (interface{}, error)) (interface{}, error) {
+	if len(pointer) == 0 {
+		// The root is handled as the single item of a wrapping dict
+		patched, err := patch(map[string]interface{}{"": document}, pathSegment{Pointer: true})
+		if err != nil {
+			return nil, err
+		}
+		return patched.(map[string]interface{})[""], nil
+	}
+	return patchPointerAt(document, pointer, 0, patch)
+}
+
+func patchPointerAt(current interface{}, pointer []pathSegment, position int, patch func(parent interface{}, segment pathSegment) (interface{}, error)) (interface{}, error) {
+	if position == len(pointer)-1 {
+		return patch(current, pointer[position])
+	}
+	child, err := lookupPointer(current, pointer, pointer[position:position+1])
+	if err != nil {
+		return nil, err
+	}
+	updated, err := patchPointerAt(child, pointer, position+1, patch)
+	if err != nil {
+		return nil, err
+	}
+	switch typed := current.(type) {
+	case map[string]interface{}:
+		typed[pointer[position].Key] = updated
+	case []interface{}:
+		typed[pointer[position].resolve(len(typed)).Index] = updated
+	}
+	return current, nil
+}
+
+// lookupPointer returns the value at the given segments within current, failing if it does not exist. The
+// whole pointer is only used in error messages.
+func lookupPointer(current interface{}, pointer []pathSegment, segments []pathSegment) (interface{}, error) {
+	for _, segment := range segments {
+		switch typed := current.(type) {
+		case map[string]interface{}:
+			item, ok := typed[segment.Key]
+			if !ok {
+				return nil, fmt.Errorf("%s does not exist", formatPath(pointer))
+			}
+			current = item
+		case []interface{}:
+			segment = segment.resolve(len(typed))
+			if !segment.IsIndex {
+				return nil, fmt.Errorf("%s is not a valid index in %s", segment.Key, formatPath(pointer))
+			}
+			if segment.Index >= len(typed) {
+				return nil, fmt.Errorf("%s does not exist", formatPath(pointer))
+			}
+			current = typed[segment.Index]
+		default:
+			return nil, fmt.Errorf("%s does not exist", formatPath(pointer))
+		}
+	}
+	return current, nil
+}
+
+func addAtPointer(parent interface{}, pointer []pathSegment, segment pathSegment, value interface{}) (interface{}, error) {
+	switch typed := parent.(type) {
+	case map[string]interface{}:
+		typed[segment.Key] = value
+		return typed, nil
+	case []interface{}:
+		segment = segment.resolve(len(typed))
+		if !segment.IsIndex {
+			return nil, fmt.Errorf("%s is not a valid index in %s", segment.Key, formatPath(pointer))
+		}
+		if segment.Index > len(typed) {
+			return nil, fmt.Errorf("%s is out of range for a list of length %d", formatPath(pointer), len(typed))
+		}
+		typed = append(typed, nil)
+		copy(typed[segment.Index+1:], typed[segment.Index:])
+		typed[segment.Index] = value
+		return typed, nil
+	default:
+		return nil, fmt.Errorf("the parent of %s does not exist or is neither a dict nor a list", formatPath(pointer))
+	}
+}
+
+func removeAtPointer(parent interface{}, pointer []pathSegment, segment pathSegment) (interface{}, error) {
+	if _, err := lookupPointer(parent, pointer, []pathSegment{segment}); err != nil {
+		return nil, err
+	}
+	switch typed := parent.(type) {
+	case map[string]interface{}:
+		delete(typed, segment.Key)
+		return typed, nil
+	default:
+		list := parent.([]interface{})
+		index := segment.resolve(len(list)).Index
+		return append(list[:index], list[index+1:]...), nil
+	}
+}
+
+// applyMergePatch applies a JSON merge patch as specified in RFC 7386 to the target, which is modified in
+// place: dictionaries are merged recursively, null values delete the matching keys and any other value
+// replaces the target.
+func applyMergePatch(target interface{}, patch interface{}) interface{} {
+	patchDict, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetDict, ok := target.(map[string]interface{})
+	if !ok {
+		targetDict = make(map[string]interface{})
+	}
+	for key, value := range patchDict {
+		if value == nil {
+			delete(targetDict, key)
+			continue
+		}
+		targetDict[key] = applyMergePatch(targetDict[key], value)
+	}
+	return targetDict
+}
+
+// diffValues returns the operations of a JSON patch turning from into to. Dictionaries are compared key by
+// key in sorted order and lists item by item, so that a minimal patch is produced for appended or updated
+// items, while values of different types are replaced as a whole.
+func diffValues(pointer string, from, to interface{}) []interface{} {
+	operations := []interface{}{}
+	switch fromTyped := from.(type) {
+	case map[string]interface{}:
+		toTyped, ok := to.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := []string{}
+		for key := range fromTyped {
+			keys = append(keys, key)
+		}
+		for key := range toTyped {
+			if _, ok := fromTyped[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			path := pointer + "/" + pathSegment{Key: key, Pointer: true}.String()
+			fromItem, inFrom := fromTyped[key]
+			toItem, inTo := toTyped[key]
+			switch {
+			case !inTo:
+				operations = append(operations, patchOperation("remove", path, nil))
+			case !inFrom:
+				operations = append(operations, patchOperation("add", path, toItem))
+			default:
+				operations = append(operations, diffValues(path, fromItem, toItem)...)
+			}
+		}
+		return operations
+	case []interface{}:
+		toTyped, ok := to.([]interface{})
+		if !ok {
+			break
+		}
+		for index := 0; index < len(fromTyped) && index < len(toTyped); index++ {
+			operations = append(operations, diffValues(fmt.Sprintf("%s/%d", pointer, index), fromTyped[index], toTyped[index])...)
+		}
+		for index := len(fromTyped) - 1; index >= len(toTyped); index-- {
+			operations = append(operations, patchOperation("remove", fmt.Sprintf("%s/%d", pointer, index), nil))
+		}
+		for index := len(fromTyped); index < len(toTyped); index++ {
+			operations = append(operations, patchOperation("add", fmt.Sprintf("%s/%d", pointer, index), toTyped[index]))
+		}
+		return operations
+	}
+	if !equalQueryValues(from, to) {
+		operations = append(operations, patchOperation("replace", pointer, to))
+	}
+	return operations
+}
+
+func patchOperation(name, path string, value interface{}) map[string]interface{} {
+	operation := map[string]interface{}{
+		"op":   name,
+		"path": path,
+	}
+	if name != "remove" {
+		operation["value"] = value
+	}
+	return operation
+}
+
+func encodePatchValue(value interface{}) string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return strings.TrimSpace(string(encoded))
+}
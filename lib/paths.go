@@ -0,0 +1,370 @@
+package lib
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nikolalohinski/gonja/v2/exec"
+)
+
+// pathSegment is either a dictionary key or a list index within a path such as `hosts[0].name`. Segments of
+// JSON pointers are keys until they are applied to a list, see resolve.
+type pathSegment struct {
+	Key     string
+	Index   int
+	IsIndex bool
+	Pointer bool
+}
+
+func (s pathSegment) String() string {
+	if s.Pointer {
+		return strings.NewReplacer("~", "~0", "/", "~1").Replace(s.Key)
+	}
+	if s.IsIndex {
+		return "[" + strconv.Itoa(s.Index) + "]"
+	}
+	return strings.NewReplacer(`\`, `\\`, ".", `\.`, "[", `\[`, "]", `\]`).Replace(s.Key)
+}
+
+// resolve turns a JSON pointer segment applied to a list of the given length into an index when it holds one.
+// The `-` segment points right after the last item.
+func (s pathSegment) resolve(length int) pathSegment {
+	if !s.Pointer {
+		return s
+	}
+	if s.Key == "-" {
+		s.Index, s.IsIndex = length, true
+		return s
+	}
+	if s.Key == "0" || s.Key != "" && s.Key[0] != '0' {
+		if index, err := strconv.Atoi(s.Key); err == nil && index >= 0 {
+			s.Index, s.IsIndex = index, true
+		}
+	}
+	return s
+}
+
+func formatPath(segments []pathSegment) string {
+	builder := strings.Builder{}
+	for index, segment := range segments {
+		if segment.Pointer {
+			builder.WriteByte('/')
+		} else if index > 0 && !segment.IsIndex {
+			builder.WriteByte('.')
+		}
+		builder.WriteString(segment.String())
+	}
+	return builder.String()
+}
+
+// parseAnyPath parses either a path as described in parsePath or a JSON pointer as specified in RFC 6901 such
+// as `/services/api/hosts/0`, which is detected by its leading slash. The empty string is the JSON pointer to
+// the whole document.
+func parseAnyPath(path string) ([]pathSegment, error) {
+	if path != "" && !strings.HasPrefix(path, "/") {
+		return parsePath(path)
+	}
+	return parsePointer(path)
+}
+
+// parsePointer splits a JSON pointer as specified in RFC 6901 into its segments.
+func parsePointer(path string) ([]pathSegment, error) {
+	segments := []pathSegment{}
+	if path == "" {
+		return segments, nil
+	}
+	if !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("JSON pointer %s must be empty or start with /", path)
+	}
+	for _, token := range strings.Split(path[1:], "/") {
+		if escapes := strings.Count(token, "~"); escapes != strings.Count(token, "~0")+strings.Count(token, "~1") {
+			return nil, fmt.Errorf("JSON pointer %s holds an invalid escape sequence, only ~0 and ~1 are allowed", path)
+		}
+		segments = append(segments, pathSegment{Key: strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~"), Pointer: true})
+	}
+	return segments, nil
+}
+
+// parsePath splits a path such as `services.api.hosts[0]` into its segments. Dots, brackets and backslashes
+// that are part of a key can be escaped with a backslash, as in `annotations.example\.com/owner`. Paths starting
+// with an index such as `[0].name` point within lists.
+func parsePath(path string) ([]pathSegment, error) {
+	segments := []pathSegment{}
+	current := strings.Builder{}
+	closed := false
+	flush := func() error {
+		if current.Len() == 0 {
+			if closed {
+				return nil
+			}
+			return fmt.Errorf("path %s holds an empty key", path)
+		}
+		segments = append(segments, pathSegment{Key: current.String()})
+		current.Reset()
+		return nil
+	}
+	for position := 0; position < len(path); position++ {
+		switch path[position] {
+		case '\\':
+			if position+1 >= len(path) || strings.IndexByte(`\.[]`, path[position+1]) < 0 {
+				return nil, fmt.Errorf("invalid escape sequence at position %d of path %s", position+1, path)
+			}
+			position++
+			current.WriteByte(path[position])
+			closed = false
+		case '.':
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			closed = false
+			if position+1 >= len(path) {
+				return nil, fmt.Errorf("path %s holds an empty key", path)
+			}
+		case '[':
+			if current.Len() > 0 {
+				if err := flush(); err != nil {
+					return nil, err
+				}
+			} else if !closed && position > 0 {
+				return nil, fmt.Errorf("path %s holds an empty key", path)
+			}
+			end := strings.IndexByte(path[position:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("index at position %d of path %s is not closed", position+1, path)
+			}
+			index, err := strconv.Atoi(path[position+1 : position+end])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("index %s at position %d of path %s is not a positive integer", path[position+1:position+end], position+1, path)
+			}
+			segments = append(segments, pathSegment{Index: index, IsIndex: true})
+			position += end
+			closed = true
+		case ']':
+			return nil, fmt.Errorf("unexpected ] at position %d of path %s", position+1, path)
+		default:
+			if closed {
+				return nil, fmt.Errorf("unexpected character at position %d of path %s, expected . or [", position+1, path)
+			}
+			current.WriteByte(path[position])
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return segments, nil
+}
+
+// nestUnderPath wraps the given value in as many dictionaries as there are keys in the dotted path.
+func nestUnderPath(path string, value interface{}) (map[string]interface{}, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	for index := len(segments) - 1; index >= 0; index-- {
+		if segments[index].IsIndex {
+			return nil, fmt.Errorf("path %s can not hold list indices", path)
+		}
+		value = map[string]interface{}{segments[index].Key: value}
+	}
+	return value.(map[string]interface{}), nil
+}
+
+// setPath sets the value at the given path within root, creating intermediate dictionaries and lists when
+// missing. List indices must point to existing items, or right after the last one to append a new item.
+func setPath(root interface{}, segments []pathSegment, value interface{}) (interface{}, error) {
+	return setPathAt(root, segments, 0, value)
+}
+
+func setPathAt(current interface{}, segments []pathSegment, position int, value interface{}) (interface{}, error) {
+	if position == len(segments) {
+		return value, nil
+	}
+	segment := segments[position]
+	if list, ok := current.([]interface{}); ok {
+		segment = segment.resolve(len(list))
+	}
+	if segment.IsIndex {
+		if current == nil {
+			current = []interface{}{}
+		}
+		list, ok := current.([]interface{})
+		if !ok {
+			return nil, traversalError(current, segments, position)
+		}
+		if segment.Index > len(list) {
+			return nil, fmt.Errorf("index %d of %s is out of range for a list of length %d", segment.Index, formatPath(segments[:position]), len(list))
+		}
+		var item interface{}
+		if segment.Index < len(list) {
+			item = list[segment.Index]
+		}
+		updated, err := setPathAt(item, segments, position+1, value)
+		if err != nil {
+			return nil, err
+		}
+		if segment.Index == len(list) {
+			return append(list, updated), nil
+		}
+		list[segment.Index] = updated
+		return list, nil
+	}
+	if current == nil {
+		current = make(map[string]interface{})
+	}
+	dict, ok := current.(map[string]interface{})
+	if !ok {
+		return nil, traversalError(current, segments, position)
+	}
+	updated, err := setPathAt(dict[segment.Key], segments, position+1, value)
+	if err != nil {
+		return nil, err
+	}
+	dict[segment.Key] = updated
+	return dict, nil
+}
+
+// lookupPath returns the value at the given path within root, or false when a key, an index or a null value
+// along the path is missing.
+func lookupPath(root *exec.Value, segments []pathSegment) (*exec.Value, bool, error) {
+	current := root
+	for position, segment := range segments {
+		if current.IsNil() {
+			return nil, false, nil
+		}
+		if current.IsList() {
+			segment = segment.resolve(current.Len())
+		}
+		if segment.IsIndex {
+			if !current.IsList() {
+				return nil, false, traversalError(current, segments, position)
+			}
+			if segment.Index >= current.Len() {
+				return nil, false, nil
+			}
+			current = exec.ToValue(current.Index(segment.Index).Interface())
+			continue
+		}
+		if !current.IsDict() {
+			return nil, false, traversalError(current, segments, position)
+		}
+		item, ok := current.GetItem(segment.Key)
+		if !ok {
+			return nil, false, nil
+		}
+		current = item
+	}
+	return current, true, nil
+}
+
+// replacePath returns a copy of root where the value at the given path is set like setPath does, or deleted
+// when remove is true. Only the dictionaries and lists along the path are copied, the rest of root is shared.
+func replacePath(root *exec.Value, segments []pathSegment, value interface{}, remove bool) (interface{}, error) {
+	return replacePathAt(root, segments, 0, value, remove)
+}
+
+func replacePathAt(current *exec.Value, segments []pathSegment, position int, value interface{}, remove bool) (interface{}, error) {
+	if position == len(segments) {
+		return value, nil
+	}
+	if remove && current.IsNil() {
+		return nil, nil
+	}
+	segment := segments[position]
+	if current.IsList() {
+		segment = segment.resolve(current.Len())
+	}
+	last := position == len(segments)-1
+	if segment.IsIndex {
+		if !current.IsNil() && !current.IsList() {
+			return nil, traversalError(current, segments, position)
+		}
+		list := []interface{}{}
+		if !current.IsNil() {
+			current.Iterate(func(_, _ int, item, _ *exec.Value) bool {
+				list = append(list, item.Interface())
+				return true
+			}, func() {})
+		}
+		if remove {
+			if segment.Index >= len(list) {
+				return list, nil
+			}
+			if last {
+				return append(list[:segment.Index], list[segment.Index+1:]...), nil
+			}
+		} else if segment.Index > len(list) {
+			return nil, fmt.Errorf("index %d of %s is out of range for a list of length %d", segment.Index, formatPath(segments[:position]), len(list))
+		}
+		item := exec.AsValue(nil)
+		if segment.Index < len(list) {
+			item = exec.ToValue(current.Index(segment.Index).Interface())
+		}
+		updated, err := replacePathAt(item, segments, position+1, value, remove)
+		if err != nil {
+			return nil, err
+		}
+		if segment.Index == len(list) {
+			return append(list, updated), nil
+		}
+		list[segment.Index] = updated
+		return list, nil
+	}
+	if !current.IsNil() && !current.IsDict() {
+		return nil, traversalError(current, segments, position)
+	}
+	dict := make(map[string]interface{})
+	if !current.IsNil() {
+		current.Iterate(func(_, _ int, key, item *exec.Value) bool {
+			dict[key.String()] = item.Interface()
+			return true
+		}, func() {})
+	}
+	item, ok := dict[segment.Key]
+	if remove {
+		if !ok {
+			return dict, nil
+		}
+		if last {
+			delete(dict, segment.Key)
+			return dict, nil
+		}
+	}
+	updated, err := replacePathAt(exec.ToValue(item), segments, position+1, value, remove)
+	if err != nil {
+		return nil, err
+	}
+	dict[segment.Key] = updated
+	return dict, nil
+}
+
+func traversalError(current interface{}, segments []pathSegment, position int) error {
+	expected := "a dict"
+	if segments[position].IsIndex {
+		expected = "a list"
+	}
+	var holds string
+	switch typed := current.(type) {
+	case nil:
+		holds = "null"
+	case map[string]interface{}:
+		holds = "a dict"
+	case []interface{}:
+		holds = "a list"
+	case *exec.Value:
+		switch {
+		case typed.IsDict():
+			holds = "a dict"
+		case typed.IsList():
+			holds = "a list"
+		default:
+			holds = fmt.Sprintf("the scalar %s", typed.String())
+		}
+	default:
+		holds = fmt.Sprintf("the scalar %v", current)
+	}
+	if position == 0 {
+		return fmt.Errorf("can not traverse %s: the root holds %s instead of %s", formatPath(segments), holds, expected)
+	}
+	return fmt.Errorf("can not traverse %s: %s holds %s instead of %s", formatPath(segments), formatPath(segments[:position]), holds, expected)
+}
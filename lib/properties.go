@@ -0,0 +1,172 @@
+package lib
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// decodeProperties parses Java properties formatted data into a flat map of strings following the
+// rules of java.util.Properties: `#` and `!` comments, `=`, `:` or whitespace separators, backslash
+// line continuations and escape sequences including `\uXXXX` unicode escapes.
+func decodeProperties(data []byte) (map[string]interface{}, error) {
+	lines := strings.Split(strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(string(data)), "\n")
+	values := make(map[string]interface{})
+	for index := 0; index < len(lines); index++ {
+		number := index + 1
+		logical := strings.TrimLeft(lines[index], " \t\f")
+		if logical == "" || logical[0] == '#' || logical[0] == '!' {
+			continue
+		}
+		for endsWithContinuation(logical) {
+			logical = logical[:len(logical)-1]
+			index++
+			if index >= len(lines) {
+				break
+			}
+			logical += strings.TrimLeft(lines[index], " \t\f")
+		}
+
+		keyEnd := len(logical)
+		for position := 0; position < len(logical); position++ {
+			if logical[position] == '\\' {
+				position++
+				continue
+			}
+			if strings.IndexByte("=: \t\f", logical[position]) >= 0 {
+				keyEnd = position
+				break
+			}
+		}
+		valueStart := keyEnd
+		for valueStart < len(logical) && strings.IndexByte(" \t\f", logical[valueStart]) >= 0 {
+			valueStart++
+		}
+		if valueStart < len(logical) && (logical[valueStart] == '=' || logical[valueStart] == ':') {
+			valueStart++
+		}
+		for valueStart < len(logical) && strings.IndexByte(" \t\f", logical[valueStart]) >= 0 {
+			valueStart++
+		}
+
+		key, err := unescapeProperty(logical[:keyEnd])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", number, err)
+		}
+		value, err := unescapeProperty(logical[valueStart:])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", number, err)
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+func endsWithContinuation(line string) bool {
+	backslashes := 0
+	for position := len(line) - 1; position >= 0 && line[position] == '\\'; position-- {
+		backslashes++
+	}
+	return backslashes%2 == 1
+}
+
+func unescapeProperty(escaped string) (string, error) {
+	if !strings.Contains(escaped, "\\") {
+		return escaped, nil
+	}
+	builder := strings.Builder{}
+	for position := 0; position < len(escaped); position++ {
+		if escaped[position] != '\\' || position+1 >= len(escaped) {
+			builder.WriteByte(escaped[position])
+			continue
+		}
+		position++
+		switch escaped[position] {
+		case 't':
+			builder.WriteByte('\t')
+		case 'n':
+			builder.WriteByte('\n')
+		case 'r':
+			builder.WriteByte('\r')
+		case 'f':
+			builder.WriteByte('\f')
+		case 'u':
+			if position+5 > len(escaped) {
+				return "", fmt.Errorf("malformed \\uXXXX escape sequence in %s", escaped)
+			}
+			code, err := strconv.ParseUint(escaped[position+1:position+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("malformed \\uXXXX escape sequence in %s", escaped)
+			}
+			position += 4
+			first := rune(code)
+			if utf16.IsSurrogate(first) && position+6 < len(escaped) && escaped[position+1:position+3] == "\\u" {
+				if low, err := strconv.ParseUint(escaped[position+3:position+7], 16, 16); err == nil {
+					if decoded := utf16.DecodeRune(first, rune(low)); decoded != unicode.ReplacementChar {
+						builder.WriteRune(decoded)
+						position += 6
+						continue
+					}
+				}
+			}
+			builder.WriteRune(first)
+		default:
+			builder.WriteByte(escaped[position])
+		}
+	}
+	return builder.String(), nil
+}
+
+// encodeProperties serializes a flat map of scalars into Java properties formatted data with sorted
+// keys, escaping characters the same way java.util.Properties does when storing. Characters outside
+// of the printable ASCII range are written as `\uXXXX` escape sequences.
+func encodeProperties(values map[string]interface{}) (string, error) {
+	builder := strings.Builder{}
+	for _, key := range sortedKeys(values) {
+		value, err := scalarToString(values[key])
+		if err != nil {
+			return "", fmt.Errorf("failed to encode %s: %s", key, err)
+		}
+		builder.WriteString(escapeProperty(key, true) + "=" + escapeProperty(value, false) + "\n")
+	}
+	return builder.String(), nil
+}
+
+func escapeProperty(value string, isKey bool) string {
+	builder := strings.Builder{}
+	for index, character := range value {
+		switch character {
+		case ' ':
+			if index == 0 || isKey {
+				builder.WriteString("\\ ")
+			} else {
+				builder.WriteByte(' ')
+			}
+		case '\t':
+			builder.WriteString("\\t")
+		case '\n':
+			builder.WriteString("\\n")
+		case '\r':
+			builder.WriteString("\\r")
+		case '\f':
+			builder.WriteString("\\f")
+		case '\\', '=', ':', '#', '!':
+			builder.WriteByte('\\')
+			builder.WriteRune(character)
+		default:
+			if character < 0x20 || character > 0x7e {
+				if character > 0xffff {
+					high, low := utf16.EncodeRune(character)
+					builder.WriteString(fmt.Sprintf("\\u%04X\\u%04X", high, low))
+				} else {
+					builder.WriteString(fmt.Sprintf("\\u%04X", character))
+				}
+				continue
+			}
+			builder.WriteRune(character)
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,686 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// This file holds the lexer and the parser of JMESPath expressions as specified in
+// https://jmespath.org/specification.html. The parser is a top down operator precedence parser, following the
+// structure of the reference implementations.
+
+type queryTokenType int
+
+const (
+	queryTokenEOF queryTokenType = iota
+	queryTokenUnquotedIdentifier
+	queryTokenQuotedIdentifier
+	queryTokenRawString
+	queryTokenLiteral
+	queryTokenNumber
+	queryTokenDot
+	queryTokenStar
+	queryTokenFlatten
+	queryTokenFilter
+	queryTokenLbracket
+	queryTokenRbracket
+	queryTokenLbrace
+	queryTokenRbrace
+	queryTokenLparen
+	queryTokenRparen
+	queryTokenComma
+	queryTokenColon
+	queryTokenPipe
+	queryTokenOr
+	queryTokenAnd
+	queryTokenNot
+	queryTokenEQ
+	queryTokenNE
+	queryTokenLT
+	queryTokenLTE
+	queryTokenGT
+	queryTokenGTE
+	queryTokenCurrent
+	queryTokenExpref
+)
+
+var queryTokenNames = map[queryTokenType]string{
+	queryTokenEOF:                "end of expression",
+	queryTokenUnquotedIdentifier: "identifier",
+	queryTokenQuotedIdentifier:   "quoted identifier",
+	queryTokenRawString:          "raw string",
+	queryTokenLiteral:            "literal",
+	queryTokenNumber:             "number",
+}
+
+var queryBindingPowers = map[queryTokenType]int{
+	queryTokenPipe:     1,
+	queryTokenOr:       2,
+	queryTokenAnd:      3,
+	queryTokenEQ:       5,
+	queryTokenNE:       5,
+	queryTokenLT:       5,
+	queryTokenLTE:      5,
+	queryTokenGT:       5,
+	queryTokenGTE:      5,
+	queryTokenFlatten:  9,
+	queryTokenStar:     20,
+	queryTokenFilter:   21,
+	queryTokenDot:      40,
+	queryTokenNot:      45,
+	queryTokenLbrace:   50,
+	queryTokenLbracket: 55,
+	queryTokenLparen:   60,
+}
+
+type queryToken struct {
+	kind     queryTokenType
+	value    string
+	position int
+}
+
+func (t queryToken) String() string {
+	if name, ok := queryTokenNames[t.kind]; ok {
+		if t.kind == queryTokenEOF {
+			return name
+		}
+		return fmt.Sprintf("%s %s", name, t.value)
+	}
+	return t.value
+}
+
+// querySyntaxError reports the position of the faulty character in the expression.
+type querySyntaxError struct {
+	expression string
+	position   int
+	message    string
+}
+
+func (e querySyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at position %d of %s: %s", e.position+1, e.expression, e.message)
+}
+
+func lexQuery(expression string) ([]queryToken, error) {
+	tokens := []queryToken{}
+	fail := func(position int, format string, args ...interface{}) ([]queryToken, error) {
+		return nil, querySyntaxError{expression: expression, position: position, message: fmt.Sprintf(format, args...)}
+	}
+	simple := map[byte]queryTokenType{
+		'.': queryTokenDot,
+		'*': queryTokenStar,
+		']': queryTokenRbracket,
+		'{': queryTokenLbrace,
+		'}': queryTokenRbrace,
+		'(': queryTokenLparen,
+		')': queryTokenRparen,
+		',': queryTokenComma,
+		':': queryTokenColon,
+		'@': queryTokenCurrent,
+	}
+	for position := 0; position < len(expression); {
+		character := expression[position]
+		if kind, ok := simple[character]; ok {
+			tokens = append(tokens, queryToken{kind: kind, value: string(character), position: position})
+			position++
+			continue
+		}
+		switch {
+		case character == ' ' || character == '\t' || character == '\n' || character == '\r':
+			position++
+		case isQueryIdentifierStart(character):
+			start := position
+			for position < len(expression) && (isQueryIdentifierStart(expression[position]) || isQueryDigit(expression[position])) {
+				position++
+			}
+			tokens = append(tokens, queryToken{kind: queryTokenUnquotedIdentifier, value: expression[start:position], position: start})
+		case character == '-' || isQueryDigit(character):
+			start := position
+			position++
+			for position < len(expression) && isQueryDigit(expression[position]) {
+				position++
+			}
+			if expression[start:position] == "-" {
+				return fail(start, "a number is expected after -")
+			}
+			tokens = append(tokens, queryToken{kind: queryTokenNumber, value: expression[start:position], position: start})
+		case character == '[':
+			switch {
+			case strings.HasPrefix(expression[position:], "[]"):
+				tokens = append(tokens, queryToken{kind: queryTokenFlatten, value: "[]", position: position})
+				position += 2
+			case strings.HasPrefix(expression[position:], "[?"):
+				tokens = append(tokens, queryToken{kind: queryTokenFilter, value: "[?", position: position})
+				position += 2
+			default:
+				tokens = append(tokens, queryToken{kind: queryTokenLbracket, value: "[", position: position})
+				position++
+			}
+		case character == '"' || character == '\'' || character == '`':
+			start := position
+			position++
+			for position < len(expression) && expression[position] != character {
+				if expression[position] == '\\' {
+					position++
+				}
+				position++
+			}
+			if position >= len(expression) {
+				return fail(start, "unclosed %c", character)
+			}
+			content := expression[start+1 : position]
+			position++
+			switch character {
+			case '"':
+				var identifier string
+				if err := json.Unmarshal([]byte(`"`+content+`"`), &identifier); err != nil {
+					return fail(start, "invalid quoted identifier %s: %s", expression[start:position], err)
+				}
+				tokens = append(tokens, queryToken{kind: queryTokenQuotedIdentifier, value: identifier, position: start})
+			case '\'':
+				tokens = append(tokens, queryToken{kind: queryTokenRawString, value: strings.ReplaceAll(content, `\'`, `'`), position: start})
+			case '`':
+				tokens = append(tokens, queryToken{kind: queryTokenLiteral, value: strings.ReplaceAll(content, "\\`", "`"), position: start})
+			}
+		case character == '|':
+			if strings.HasPrefix(expression[position:], "||") {
+				tokens = append(tokens, queryToken{kind: queryTokenOr, value: "||", position: position})
+				position += 2
+			} else {
+				tokens = append(tokens, queryToken{kind: queryTokenPipe, value: "|", position: position})
+				position++
+			}
+		case character == '&':
+			if strings.HasPrefix(expression[position:], "&&") {
+				tokens = append(tokens, queryToken{kind: queryTokenAnd, value: "&&", position: position})
+				position += 2
+			} else {
+				tokens = append(tokens, queryToken{kind: queryTokenExpref, value: "&", position: position})
+				position++
+			}
+		case character == '!':
+			if strings.HasPrefix(expression[position:], "!=") {
+				tokens = append(tokens, queryToken{kind: queryTokenNE, value: "!=", position: position})
+				position += 2
+			} else {
+				tokens = append(tokens, queryToken{kind: queryTokenNot, value: "!", position: position})
+				position++
+			}
+		case character == '=':
+			if !strings.HasPrefix(expression[position:], "==") {
+				return fail(position, "unexpected =, did you mean ==")
+			}
+			tokens = append(tokens, queryToken{kind: queryTokenEQ, value: "==", position: position})
+			position += 2
+		case character == '<' || character == '>':
+			kinds := map[string]queryTokenType{"<": queryTokenLT, "<=": queryTokenLTE, ">": queryTokenGT, ">=": queryTokenGTE}
+			operator := string(character)
+			if strings.HasPrefix(expression[position+1:], "=") {
+				operator += "="
+			}
+			tokens = append(tokens, queryToken{kind: kinds[operator], value: operator, position: position})
+			position += len(operator)
+		default:
+			return fail(position, "unexpected character %c", character)
+		}
+	}
+	return append(tokens, queryToken{kind: queryTokenEOF, position: len(expression)}), nil
+}
+
+func isQueryIdentifierStart(character byte) bool {
+	return character == '_' || (character >= 'a' && character <= 'z') || (character >= 'A' && character <= 'Z')
+}
+
+func isQueryDigit(character byte) bool {
+	return character >= '0' && character <= '9'
+}
+
+type queryNodeKind int
+
+const (
+	queryNodeField queryNodeKind = iota
+	queryNodeSubexpression
+	queryNodeIndexExpression
+	queryNodeIndex
+	queryNodeSlice
+	queryNodeProjection
+	queryNodeValueProjection
+	queryNodeFilterProjection
+	queryNodeFlatten
+	queryNodeIdentity
+	queryNodeCurrent
+	queryNodeLiteral
+	queryNodeMultiSelectList
+	queryNodeMultiSelectHash
+	queryNodeKeyValuePair
+	queryNodeComparator
+	queryNodeOr
+	queryNodeAnd
+	queryNodeNot
+	queryNodePipe
+	queryNodeFunction
+	queryNodeExpref
+)
+
+type queryNode struct {
+	kind     queryNodeKind
+	value    interface{}
+	children []queryNode
+}
+
+type queryParser struct {
+	expression string
+	tokens     []queryToken
+	index      int
+}
+
+// parseQuery compiles a JMESPath expression into its abstract syntax tree.
+func parseQuery(expression string) (queryNode, error) {
+	tokens, err := lexQuery(expression)
+	if err != nil {
+		return queryNode{}, err
+	}
+	parser := &queryParser{expression: expression, tokens: tokens}
+	node, err := parser.parseExpression(0)
+	if err != nil {
+		return queryNode{}, err
+	}
+	if parser.current().kind != queryTokenEOF {
+		return queryNode{}, parser.fail(parser.lookahead(0), "unexpected %s", parser.lookahead(0))
+	}
+	return node, nil
+}
+
+func (p *queryParser) current() queryToken {
+	return p.lookahead(0)
+}
+
+func (p *queryParser) lookahead(offset int) queryToken {
+	if p.index+offset >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.index+offset]
+}
+
+func (p *queryParser) advance() queryToken {
+	token := p.current()
+	if p.index < len(p.tokens)-1 {
+		p.index++
+	}
+	return token
+}
+
+func (p *queryParser) match(kind queryTokenType, expected string) error {
+	if p.current().kind != kind {
+		return p.fail(p.current(), "expected %s but found %s", expected, p.current())
+	}
+	p.advance()
+	return nil
+}
+
+func (p *queryParser) fail(token queryToken, format string, args ...interface{}) error {
+	return querySyntaxError{expression: p.expression, position: token.position, message: fmt.Sprintf(format, args...)}
+}
+
+func (p *queryParser) parseExpression(bindingPower int) (queryNode, error) {
+	left, err := p.nud(p.advance())
+	if err != nil {
+		return queryNode{}, err
+	}
+	for bindingPower < queryBindingPowers[p.current().kind] {
+		if left, err = p.led(p.advance(), left); err != nil {
+			return queryNode{}, err
+		}
+	}
+	return left, nil
+}
+
+func (p *queryParser) nud(token queryToken) (queryNode, error) {
+	identity := queryNode{kind: queryNodeIdentity}
+	switch token.kind {
+	case queryTokenLiteral:
+		value, err := decodeQueryLiteral(token.value)
+		if err != nil {
+			return queryNode{}, p.fail(token, "invalid literal `%s`: %s", token.value, err)
+		}
+		return queryNode{kind: queryNodeLiteral, value: value}, nil
+	case queryTokenRawString:
+		return queryNode{kind: queryNodeLiteral, value: token.value}, nil
+	case queryTokenUnquotedIdentifier:
+		return queryNode{kind: queryNodeField, value: token.value}, nil
+	case queryTokenQuotedIdentifier:
+		if p.current().kind == queryTokenLparen {
+			return queryNode{}, p.fail(token, "quoted identifier %q can not be used as a function name", token.value)
+		}
+		return queryNode{kind: queryNodeField, value: token.value}, nil
+	case queryTokenStar:
+		right := identity
+		if p.current().kind != queryTokenRbracket {
+			var err error
+			if right, err = p.parseProjectionRHS(queryBindingPowers[queryTokenStar]); err != nil {
+				return queryNode{}, err
+			}
+		}
+		return queryNode{kind: queryNodeValueProjection, children: []queryNode{identity, right}}, nil
+	case queryTokenFilter:
+		return p.parseFilter(identity)
+	case queryTokenLbrace:
+		return p.parseMultiSelectHash()
+	case queryTokenFlatten:
+		right, err := p.parseProjectionRHS(queryBindingPowers[queryTokenFlatten])
+		if err != nil {
+			return queryNode{}, err
+		}
+		left := queryNode{kind: queryNodeFlatten, children: []queryNode{identity}}
+		return queryNode{kind: queryNodeProjection, children: []queryNode{left, right}}, nil
+	case queryTokenLbracket:
+		switch {
+		case p.current().kind == queryTokenNumber || p.current().kind == queryTokenColon:
+			right, err := p.parseIndexExpression()
+			if err != nil {
+				return queryNode{}, err
+			}
+			return p.projectIfSlice(identity, right)
+		case p.current().kind == queryTokenStar && p.lookahead(1).kind == queryTokenRbracket:
+			p.advance()
+			p.advance()
+			right, err := p.parseProjectionRHS(queryBindingPowers[queryTokenStar])
+			if err != nil {
+				return queryNode{}, err
+			}
+			return queryNode{kind: queryNodeProjection, children: []queryNode{identity, right}}, nil
+		default:
+			return p.parseMultiSelectList()
+		}
+	case queryTokenCurrent:
+		return queryNode{kind: queryNodeCurrent}, nil
+	case queryTokenExpref:
+		expression, err := p.parseExpression(queryBindingPowers[queryTokenExpref])
+		if err != nil {
+			return queryNode{}, err
+		}
+		return queryNode{kind: queryNodeExpref, children: []queryNode{expression}}, nil
+	case queryTokenNot:
+		expression, err := p.parseExpression(queryBindingPowers[queryTokenNot])
+		if err != nil {
+			return queryNode{}, err
+		}
+		return queryNode{kind: queryNodeNot, children: []queryNode{expression}}, nil
+	case queryTokenLparen:
+		expression, err := p.parseExpression(0)
+		if err != nil {
+			return queryNode{}, err
+		}
+		if err := p.match(queryTokenRparen, ")"); err != nil {
+			return queryNode{}, err
+		}
+		return expression, nil
+	case queryTokenEOF:
+		return queryNode{}, p.fail(token, "incomplete expression")
+	default:
+		return queryNode{}, p.fail(token, "unexpected %s", token)
+	}
+}
+
+func (p *queryParser) led(token queryToken, left queryNode) (queryNode, error) {
+	switch token.kind {
+	case queryTokenDot:
+		if p.current().kind != queryTokenStar {
+			right, err := p.parseDotRHS(queryBindingPowers[queryTokenDot])
+			if err != nil {
+				return queryNode{}, err
+			}
+			return queryNode{kind: queryNodeSubexpression, children: []queryNode{left, right}}, nil
+		}
+		p.advance()
+		right, err := p.parseProjectionRHS(queryBindingPowers[queryTokenDot])
+		if err != nil {
+			return queryNode{}, err
+		}
+		return queryNode{kind: queryNodeValueProjection, children: []queryNode{left, right}}, nil
+	case queryTokenPipe, queryTokenOr, queryTokenAnd:
+		right, err := p.parseExpression(queryBindingPowers[token.kind])
+		if err != nil {
+			return queryNode{}, err
+		}
+		kind := map[queryTokenType]queryNodeKind{queryTokenPipe: queryNodePipe, queryTokenOr: queryNodeOr, queryTokenAnd: queryNodeAnd}[token.kind]
+		return queryNode{kind: kind, children: []queryNode{left, right}}, nil
+	case queryTokenLparen:
+		if left.kind != queryNodeField {
+			return queryNode{}, p.fail(token, "unexpected (")
+		}
+		arguments := []queryNode{}
+		for p.current().kind != queryTokenRparen {
+			argument, err := p.parseExpression(0)
+			if err != nil {
+				return queryNode{}, err
+			}
+			if p.current().kind == queryTokenComma {
+				p.advance()
+			} else if p.current().kind != queryTokenRparen {
+				return queryNode{}, p.fail(p.current(), "expected , or ) but found %s", p.current())
+			}
+			arguments = append(arguments, argument)
+		}
+		p.advance()
+		return queryNode{kind: queryNodeFunction, value: left.value, children: arguments}, nil
+	case queryTokenFilter:
+		return p.parseFilter(left)
+	case queryTokenFlatten:
+		right, err := p.parseProjectionRHS(queryBindingPowers[queryTokenFlatten])
+		if err != nil {
+			return queryNode{}, err
+		}
+		flattened := queryNode{kind: queryNodeFlatten, children: []queryNode{left}}
+		return queryNode{kind: queryNodeProjection, children: []queryNode{flattened, right}}, nil
+	case queryTokenEQ, queryTokenNE, queryTokenLT, queryTokenLTE, queryTokenGT, queryTokenGTE:
+		right, err := p.parseExpression(queryBindingPowers[token.kind])
+		if err != nil {
+			return queryNode{}, err
+		}
+		return queryNode{kind: queryNodeComparator, value: token.kind, children: []queryNode{left, right}}, nil
+	case queryTokenLbracket:
+		if p.current().kind == queryTokenNumber || p.current().kind == queryTokenColon {
+			right, err := p.parseIndexExpression()
+			if err != nil {
+				return queryNode{}, err
+			}
+			return p.projectIfSlice(left, right)
+		}
+		if err := p.match(queryTokenStar, "a number, : or *"); err != nil {
+			return queryNode{}, err
+		}
+		if err := p.match(queryTokenRbracket, "]"); err != nil {
+			return queryNode{}, err
+		}
+		right, err := p.parseProjectionRHS(queryBindingPowers[queryTokenStar])
+		if err != nil {
+			return queryNode{}, err
+		}
+		return queryNode{kind: queryNodeProjection, children: []queryNode{left, right}}, nil
+	default:
+		return queryNode{}, p.fail(token, "unexpected %s", token)
+	}
+}
+
+func (p *queryParser) parseIndexExpression() (queryNode, error) {
+	if p.lookahead(0).kind == queryTokenColon || p.lookahead(1).kind == queryTokenColon {
+		return p.parseSliceExpression()
+	}
+	index, err := strconv.Atoi(p.current().value)
+	if err != nil {
+		return queryNode{}, p.fail(p.current(), "invalid index %s", p.current().value)
+	}
+	p.advance()
+	if err := p.match(queryTokenRbracket, "]"); err != nil {
+		return queryNode{}, err
+	}
+	return queryNode{kind: queryNodeIndex, value: index}, nil
+}
+
+func (p *queryParser) parseSliceExpression() (queryNode, error) {
+	parts := []*int{nil, nil, nil}
+	index := 0
+	for p.current().kind != queryTokenRbracket && index < 3 {
+		switch p.current().kind {
+		case queryTokenColon:
+			index++
+		case queryTokenNumber:
+			value, err := strconv.Atoi(p.current().value)
+			if err != nil {
+				return queryNode{}, p.fail(p.current(), "invalid slice bound %s", p.current().value)
+			}
+			if index == 2 && value == 0 {
+				return queryNode{}, p.fail(p.current(), "slice step can not be 0")
+			}
+			parts[index] = &value
+		default:
+			return queryNode{}, p.fail(p.current(), "expected a number, : or ] but found %s", p.current())
+		}
+		p.advance()
+	}
+	if err := p.match(queryTokenRbracket, "]"); err != nil {
+		return queryNode{}, err
+	}
+	return queryNode{kind: queryNodeSlice, value: parts}, nil
+}
+
+func (p *queryParser) projectIfSlice(left, right queryNode) (queryNode, error) {
+	index := queryNode{kind: queryNodeIndexExpression, children: []queryNode{left, right}}
+	if right.kind != queryNodeSlice {
+		return index, nil
+	}
+	projected, err := p.parseProjectionRHS(queryBindingPowers[queryTokenStar])
+	if err != nil {
+		return queryNode{}, err
+	}
+	return queryNode{kind: queryNodeProjection, children: []queryNode{index, projected}}, nil
+}
+
+func (p *queryParser) parseFilter(left queryNode) (queryNode, error) {
+	condition, err := p.parseExpression(0)
+	if err != nil {
+		return queryNode{}, err
+	}
+	if err := p.match(queryTokenRbracket, "]"); err != nil {
+		return queryNode{}, err
+	}
+	right := queryNode{kind: queryNodeIdentity}
+	if p.current().kind != queryTokenFlatten {
+		if right, err = p.parseProjectionRHS(queryBindingPowers[queryTokenFilter]); err != nil {
+			return queryNode{}, err
+		}
+	}
+	return queryNode{kind: queryNodeFilterProjection, children: []queryNode{left, right, condition}}, nil
+}
+
+func (p *queryParser) parseDotRHS(bindingPower int) (queryNode, error) {
+	switch p.current().kind {
+	case queryTokenUnquotedIdentifier, queryTokenQuotedIdentifier, queryTokenStar:
+		return p.parseExpression(bindingPower)
+	case queryTokenLbracket:
+		p.advance()
+		return p.parseMultiSelectList()
+	case queryTokenLbrace:
+		p.advance()
+		return p.parseMultiSelectHash()
+	default:
+		return queryNode{}, p.fail(p.current(), "expected an identifier, *, [ or { after . but found %s", p.current())
+	}
+}
+
+func (p *queryParser) parseProjectionRHS(bindingPower int) (queryNode, error) {
+	switch current := p.current(); {
+	case queryBindingPowers[current.kind] < 10:
+		return queryNode{kind: queryNodeIdentity}, nil
+	case current.kind == queryTokenLbracket || current.kind == queryTokenFilter:
+		return p.parseExpression(bindingPower)
+	case current.kind == queryTokenDot:
+		p.advance()
+		return p.parseDotRHS(bindingPower)
+	default:
+		return queryNode{}, p.fail(current, "unexpected %s after a projection", current)
+	}
+}
+
+func (p *queryParser) parseMultiSelectList() (queryNode, error) {
+	expressions := []queryNode{}
+	for {
+		expression, err := p.parseExpression(0)
+		if err != nil {
+			return queryNode{}, err
+		}
+		expressions = append(expressions, expression)
+		if p.current().kind == queryTokenRbracket {
+			break
+		}
+		if err := p.match(queryTokenComma, ", or ]"); err != nil {
+			return queryNode{}, err
+		}
+	}
+	p.advance()
+	return queryNode{kind: queryNodeMultiSelectList, children: expressions}, nil
+}
+
+func (p *queryParser) parseMultiSelectHash() (queryNode, error) {
+	pairs := []queryNode{}
+	for {
+		key := p.current()
+		if key.kind != queryTokenUnquotedIdentifier && key.kind != queryTokenQuotedIdentifier {
+			return queryNode{}, p.fail(key, "expected an identifier but found %s", key)
+		}
+		p.advance()
+		if err := p.match(queryTokenColon, ":"); err != nil {
+			return queryNode{}, err
+		}
+		value, err := p.parseExpression(0)
+		if err != nil {
+			return queryNode{}, err
+		}
+		pairs = append(pairs, queryNode{kind: queryNodeKeyValuePair, value: key.value, children: []queryNode{value}})
+		if p.current().kind == queryTokenRbrace {
+			p.advance()
+			break
+		}
+		if err := p.match(queryTokenComma, ", or }"); err != nil {
+			return queryNode{}, err
+		}
+	}
+	return queryNode{kind: queryNodeMultiSelectHash, children: pairs}, nil
+}
+
+// decodeQueryLiteral decodes the JSON content of a literal, keeping integers as integers.
+func decodeQueryLiteral(literal string) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(literal)))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("unexpected content after the value")
+	}
+	return normalizeQueryNumbers(value), nil
+}
+
+func normalizeQueryNumbers(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case json.Number:
+		if integer, err := strconv.Atoi(typed.String()); err == nil {
+			return integer
+		}
+		float, _ := typed.Float64()
+		return float
+	case map[string]interface{}:
+		for key, item := range typed {
+			typed[key] = normalizeQueryNumbers(item)
+		}
+	case []interface{}:
+		for index, item := range typed {
+			typed[index] = normalizeQueryNumbers(item)
+		}
+	}
+	return value
+}
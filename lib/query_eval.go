@@ -0,0 +1,412 @@
+package lib
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/nikolalohinski/gonja/v2/exec"
+)
+
+// queryExpref is the value of an expression reference such as `&name`, which is only meaningful as the
+// argument of functions like sort_by.
+type queryExpref struct {
+	node queryNode
+}
+
+// evaluateQuery parses and evaluates a JMESPath expression against a value made of dictionaries with string
+// keys, lists, strings, numbers, booleans and nil.
+func evaluateQuery(expression string, value interface{}) (interface{}, error) {
+	node, err := parseQuery(expression)
+	if err != nil {
+		return nil, err
+	}
+	return node.evaluate(value)
+}
+
+// evaluateValue evaluates the expression against a template value.
+func (n queryNode) evaluateValue(in *exec.Value) (interface{}, error) {
+	data := in.ToGoSimpleType(false)
+	if err, ok := data.(error); ok {
+		return nil, err
+	}
+	result, err := n.evaluate(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate query: %s", err)
+	}
+	return result, nil
+}
+
+func (n queryNode) evaluate(value interface{}) (interface{}, error) {
+	switch n.kind {
+	case queryNodeField:
+		if dict, ok := value.(map[string]interface{}); ok {
+			return dict[n.value.(string)], nil
+		}
+		return nil, nil
+	case queryNodeSubexpression, queryNodeIndexExpression:
+		left, err := n.children[0].evaluate(value)
+		if err != nil {
+			return nil, err
+		}
+		return n.children[1].evaluate(left)
+	case queryNodeIndex:
+		list, ok := value.([]interface{})
+		if !ok {
+			return nil, nil
+		}
+		index := n.value.(int)
+		if index < 0 {
+			index += len(list)
+		}
+		if index < 0 || index >= len(list) {
+			return nil, nil
+		}
+		return list[index], nil
+	case queryNodeSlice:
+		list, ok := value.([]interface{})
+		if !ok {
+			return nil, nil
+		}
+		return sliceQueryList(list, n.value.([]*int)), nil
+	case queryNodeProjection, queryNodeValueProjection:
+		left, err := n.children[0].evaluate(value)
+		if err != nil {
+			return nil, err
+		}
+		items, ok := left.([]interface{})
+		if n.kind == queryNodeValueProjection {
+			dict, isDict := left.(map[string]interface{})
+			items, ok = sortedQueryValues(dict), isDict
+		}
+		if !ok {
+			return nil, nil
+		}
+		projected := []interface{}{}
+		for _, item := range items {
+			result, err := n.children[1].evaluate(item)
+			if err != nil {
+				return nil, err
+			}
+			if result != nil {
+				projected = append(projected, result)
+			}
+		}
+		return projected, nil
+	case queryNodeFilterProjection:
+		left, err := n.children[0].evaluate(value)
+		if err != nil {
+			return nil, err
+		}
+		items, ok := left.([]interface{})
+		if !ok {
+			return nil, nil
+		}
+		filtered := []interface{}{}
+		for _, item := range items {
+			condition, err := n.children[2].evaluate(item)
+			if err != nil {
+				return nil, err
+			}
+			if !isQueryTruthy(condition) {
+				continue
+			}
+			result, err := n.children[1].evaluate(item)
+			if err != nil {
+				return nil, err
+			}
+			if result != nil {
+				filtered = append(filtered, result)
+			}
+		}
+		return filtered, nil
+	case queryNodeFlatten:
+		left, err := n.children[0].evaluate(value)
+		if err != nil {
+			return nil, err
+		}
+		items, ok := left.([]interface{})
+		if !ok {
+			return nil, nil
+		}
+		flattened := []interface{}{}
+		for _, item := range items {
+			if nested, ok := item.([]interface{}); ok {
+				flattened = append(flattened, nested...)
+			} else {
+				flattened = append(flattened, item)
+			}
+		}
+		return flattened, nil
+	case queryNodeIdentity, queryNodeCurrent:
+		return value, nil
+	case queryNodeLiteral:
+		return deepCopy(n.value), nil
+	case queryNodeMultiSelectList:
+		if value == nil {
+			return nil, nil
+		}
+		selected := make([]interface{}, 0, len(n.children))
+		for _, child := range n.children {
+			result, err := child.evaluate(value)
+			if err != nil {
+				return nil, err
+			}
+			selected = append(selected, result)
+		}
+		return selected, nil
+	case queryNodeMultiSelectHash:
+		if value == nil {
+			return nil, nil
+		}
+		selected := make(map[string]interface{}, len(n.children))
+		for _, pair := range n.children {
+			result, err := pair.children[0].evaluate(value)
+			if err != nil {
+				return nil, err
+			}
+			selected[pair.value.(string)] = result
+		}
+		return selected, nil
+	case queryNodeComparator:
+		left, err := n.children[0].evaluate(value)
+		if err != nil {
+			return nil, err
+		}
+		right, err := n.children[1].evaluate(value)
+		if err != nil {
+			return nil, err
+		}
+		return compareQueryValues(n.value.(queryTokenType), left, right), nil
+	case queryNodeOr:
+		left, err := n.children[0].evaluate(value)
+		if err != nil || isQueryTruthy(left) {
+			return left, err
+		}
+		return n.children[1].evaluate(value)
+	case queryNodeAnd:
+		left, err := n.children[0].evaluate(value)
+		if err != nil || !isQueryTruthy(left) {
+			return left, err
+		}
+		return n.children[1].evaluate(value)
+	case queryNodeNot:
+		result, err := n.children[0].evaluate(value)
+		if err != nil {
+			return nil, err
+		}
+		return !isQueryTruthy(result), nil
+	case queryNodePipe:
+		left, err := n.children[0].evaluate(value)
+		if err != nil {
+			return nil, err
+		}
+		return n.children[1].evaluate(left)
+	case queryNodeFunction:
+		arguments := make([]interface{}, 0, len(n.children))
+		for _, child := range n.children {
+			if child.kind == queryNodeExpref {
+				arguments = append(arguments, queryExpref{node: child.children[0]})
+				continue
+			}
+			result, err := child.evaluate(value)
+			if err != nil {
+				return nil, err
+			}
+			arguments = append(arguments, result)
+		}
+		return callQueryFunction(n.value.(string), arguments)
+	case queryNodeExpref:
+		return queryExpref{node: n.children[0]}, nil
+	default:
+		return nil, fmt.Errorf("unsupported expression")
+	}
+}
+
+func sliceQueryList(list []interface{}, parts []*int) []interface{} {
+	step := 1
+	if parts[2] != nil {
+		step = *parts[2]
+	}
+	length := len(list)
+	bound := func(part *int, fallback int) int {
+		if part == nil {
+			return fallback
+		}
+		value := *part
+		if value < 0 {
+			value += length
+			if value < 0 {
+				if step < 0 {
+					return -1
+				}
+				return 0
+			}
+		} else if value >= length {
+			if step < 0 {
+				return length - 1
+			}
+			return length
+		}
+		return value
+	}
+	sliced := []interface{}{}
+	if step > 0 {
+		for index := bound(parts[0], 0); index < bound(parts[1], length); index += step {
+			sliced = append(sliced, list[index])
+		}
+	} else {
+		for index := bound(parts[0], length-1); index > bound(parts[1], -1); index += step {
+			sliced = append(sliced, list[index])
+		}
+	}
+	return sliced
+}
+
+func isQueryTruthy(value interface{}) bool {
+	switch typed := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return typed
+	case string:
+		return typed != ""
+	case []interface{}:
+		return len(typed) > 0
+	case map[string]interface{}:
+		return len(typed) > 0
+	default:
+		return true
+	}
+}
+
+func compareQueryValues(operator queryTokenType, left, right interface{}) interface{} {
+	switch operator {
+	case queryTokenEQ:
+		return equalQueryValues(left, right)
+	case queryTokenNE:
+		return !equalQueryValues(left, right)
+	}
+	var comparison int
+	leftNumber, leftIsNumber := queryNumber(left)
+	rightNumber, rightIsNumber := queryNumber(right)
+	leftString, leftIsString := left.(string)
+	rightString, rightIsString := right.(string)
+	switch {
+	case leftIsNumber && rightIsNumber:
+		comparison = compareFloats(leftNumber, rightNumber)
+	case leftIsString && rightIsString:
+		comparison = strings.Compare(leftString, rightString)
+	default:
+		return nil
+	}
+	switch operator {
+	case queryTokenLT:
+		return comparison < 0
+	case queryTokenLTE:
+		return comparison <= 0
+	case queryTokenGT:
+		return comparison > 0
+	default:
+		return comparison >= 0
+	}
+}
+
+func compareFloats(left, right float64) int {
+	switch {
+	case left < right:
+		return -1
+	case left > right:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func equalQueryValues(left, right interface{}) bool {
+	if leftNumber, ok := queryNumber(left); ok {
+		rightNumber, ok := queryNumber(right)
+		return ok && leftNumber == rightNumber
+	}
+	switch typed := left.(type) {
+	case []interface{}:
+		other, ok := right.([]interface{})
+		if !ok || len(typed) != len(other) {
+			return false
+		}
+		for index := range typed {
+			if !equalQueryValues(typed[index], other[index]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		other, ok := right.(map[string]interface{})
+		if !ok || len(typed) != len(other) {
+			return false
+		}
+		for key, item := range typed {
+			otherItem, ok := other[key]
+			if !ok || !equalQueryValues(item, otherItem) {
+				return false
+			}
+		}
+		return true
+	default:
+		return left == right
+	}
+}
+
+// queryNumber converts any Go number into a float64 to compare and compute numbers of different types.
+func queryNumber(value interface{}) (float64, bool) {
+	switch typed := value.(type) {
+	case int:
+		return float64(typed), true
+	case int8:
+		return float64(typed), true
+	case int16:
+		return float64(typed), true
+	case int32:
+		return float64(typed), true
+	case int64:
+		return float64(typed), true
+	case uint:
+		return float64(typed), true
+	case uint8:
+		return float64(typed), true
+	case uint16:
+		return float64(typed), true
+	case uint32:
+		return float64(typed), true
+	case uint64:
+		return float64(typed), true
+	case float32:
+		return float64(typed), true
+	case float64:
+		return typed, true
+	default:
+		return 0, false
+	}
+}
+
+// queryNumberResult keeps integral results as integers so that they render without a decimal part.
+func queryNumberResult(value float64) interface{} {
+	if value == math.Trunc(value) && math.Abs(value) < 1<<53 {
+		return int(value)
+	}
+	return value
+}
+
+func sortedQueryValues(dict map[string]interface{}) []interface{} {
+	keys := make([]string, 0, len(dict))
+	for key := range dict {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	values := make([]interface{}, 0, len(keys))
+	for _, key := range keys {
+		values = append(values, dict[key])
+	}
+	return values
+}
@@ -0,0 +1,372 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dustin/go-humanize"
+)
+
+type queryType string
+
+const (
+	queryTypeAny     queryType = "any"
+	queryTypeNumber  queryType = "number"
+	queryTypeString  queryType = "string"
+	queryTypeBoolean queryType = "boolean"
+	queryTypeArray   queryType = "array"
+	queryTypeObject  queryType = "object"
+	queryTypeNull    queryType = "null"
+	queryTypeExpref  queryType = "expref"
+)
+
+// queryFunction describes the signature of a JMESPath built-in function, where each argument accepts one or
+// more types. The last argument is repeated when the function is variadic.
+type queryFunction struct {
+	arguments [][]queryType
+	variadic  bool
+	call      func(arguments []interface{}) (interface{}, error)
+}
+
+var queryFunctions map[string]queryFunction
+
+func init() {
+	number := []queryType{queryTypeNumber}
+	str := []queryType{queryTypeString}
+	array := []queryType{queryTypeArray}
+	object := []queryType{queryTypeObject}
+	anything := []queryType{queryTypeAny}
+	expref := []queryType{queryTypeExpref}
+	queryFunctions = map[string]queryFunction{
+		"abs": {arguments: [][]queryType{number}, call: func(arguments []interface{}) (interface{}, error) {
+			value, _ := queryNumber(arguments[0])
+			return queryNumberResult(math.Abs(value)), nil
+		}},
+		"avg": {arguments: [][]queryType{array}, call: func(arguments []interface{}) (interface{}, error) {
+			numbers, err := queryNumbers("avg", arguments[0])
+			if err != nil || len(numbers) == 0 {
+				return nil, err
+			}
+			sum := 0.0
+			for _, number := range numbers {
+				sum += number
+			}
+			return sum / float64(len(numbers)), nil
+		}},
+		"ceil": {arguments: [][]queryType{number}, call: func(arguments []interface{}) (interface{}, error) {
+			value, _ := queryNumber(arguments[0])
+			return queryNumberResult(math.Ceil(value)), nil
+		}},
+		"contains": {arguments: [][]queryType{{queryTypeArray, queryTypeString}, anything}, call: func(arguments []interface{}) (interface{}, error) {
+			if subject, ok := arguments[0].(string); ok {
+				search, ok := arguments[1].(string)
+				return ok && strings.Contains(subject, search), nil
+			}
+			for _, item := range arguments[0].([]interface{}) {
+				if equalQueryValues(item, arguments[1]) {
+					return true, nil
+				}
+			}
+			return false, nil
+		}},
+		"ends_with": {arguments: [][]queryType{str, str}, call: func(arguments []interface{}) (interface{}, error) {
+			return strings.HasSuffix(arguments[0].(string), arguments[1].(string)), nil
+		}},
+		"floor": {arguments: [][]queryType{number}, call: func(arguments []interface{}) (interface{}, error) {
+			value, _ := queryNumber(arguments[0])
+			return queryNumberResult(math.Floor(value)), nil
+		}},
+		"join": {arguments: [][]queryType{str, array}, call: func(arguments []interface{}) (interface{}, error) {
+			items := []string{}
+			for _, item := range arguments[1].([]interface{}) {
+				text, ok := item.(string)
+				if !ok {
+					return nil, fmt.Errorf("join expects an array of strings but got an item of type %s", queryTypeOf(item))
+				}
+				items = append(items, text)
+			}
+			return strings.Join(items, arguments[0].(string)), nil
+		}},
+		"keys": {arguments: [][]queryType{object}, call: func(arguments []interface{}) (interface{}, error) {
+			keys := []interface{}{}
+			for _, key := range sortedQueryKeys(arguments[0].(map[string]interface{})) {
+				keys = append(keys, key)
+			}
+			return keys, nil
+		}},
+		"length": {arguments: [][]queryType{{queryTypeString, queryTypeArray, queryTypeObject}}, call: func(arguments []interface{}) (interface{}, error) {
+			switch typed := arguments[0].(type) {
+			case string:
+				return utf8.RuneCountInString(typed), nil
+			case []interface{}:
+				return len(typed), nil
+			default:
+				return len(typed.(map[string]interface{})), nil
+			}
+		}},
+		"map": {arguments: [][]queryType{expref, array}, call: func(arguments []interface{}) (interface{}, error) {
+			mapped := []interface{}{}
+			for _, item := range arguments[1].([]interface{}) {
+				result, err := arguments[0].(queryExpref).node.evaluate(item)
+				if err != nil {
+					return nil, err
+				}
+				mapped = append(mapped, result)
+			}
+			return mapped, nil
+		}},
+		"max": {arguments: [][]queryType{array}, call: func(arguments []interface{}) (interface{}, error) {
+			return extremeQueryValue("max", arguments[0].([]interface{}), nil, 1)
+		}},
+		"max_by": {arguments: [][]queryType{array, expref}, call: func(arguments []interface{}) (interface{}, error) {
+			expression := arguments[1].(queryExpref)
+			return extremeQueryValue("max_by", arguments[0].([]interface{}), &expression, 1)
+		}},
+		"merge": {arguments: [][]queryType{object}, variadic: true, call: func(arguments []interface{}) (interface{}, error) {
+			merged := make(map[string]interface{})
+			for _, argument := range arguments {
+				for key, value := range argument.(map[string]interface{}) {
+					merged[key] = value
+				}
+			}
+			return merged, nil
+		}},
+		"min": {arguments: [][]queryType{array}, call: func(arguments []interface{}) (interface{}, error) {
+			return extremeQueryValue("min", arguments[0].([]interface{}), nil, -1)
+		}},
+		"min_by": {arguments: [][]queryType{array, expref}, call: func(arguments []interface{}) (interface{}, error) {
+			expression := arguments[1].(queryExpref)
+			return extremeQueryValue("min_by", arguments[0].([]interface{}), &expression, -1)
+		}},
+		"not_null": {arguments: [][]queryType{anything}, variadic: true, call: func(arguments []interface{}) (interface{}, error) {
+			for _, argument := range arguments {
+				if argument != nil {
+					return argument, nil
+				}
+			}
+			return nil, nil
+		}},
+		"reverse": {arguments: [][]queryType{{queryTypeString, queryTypeArray}}, call: func(arguments []interface{}) (interface{}, error) {
+			if text, ok := arguments[0].(string); ok {
+				runes := []rune(text)
+				for left, right := 0, len(runes)-1; left < right; left, right = left+1, right-1 {
+					runes[left], runes[right] = runes[right], runes[left]
+				}
+				return string(runes), nil
+			}
+			items := arguments[0].([]interface{})
+			reversed := make([]interface{}, len(items))
+			for index, item := range items {
+				reversed[len(items)-1-index] = item
+			}
+			return reversed, nil
+		}},
+		"sort": {arguments: [][]queryType{array}, call: func(arguments []interface{}) (interface{}, error) {
+			return sortQueryValues("sort", arguments[0].([]interface{}), nil)
+		}},
+		"sort_by": {arguments: [][]queryType{array, expref}, call: func(arguments []interface{}) (interface{}, error) {
+			expression := arguments[1].(queryExpref)
+			return sortQueryValues("sort_by", arguments[0].([]interface{}), &expression)
+		}},
+		"starts_with": {arguments: [][]queryType{str, str}, call: func(arguments []interface{}) (interface{}, error) {
+			return strings.HasPrefix(arguments[0].(string), arguments[1].(string)), nil
+		}},
+		"sum": {arguments: [][]queryType{array}, call: func(arguments []interface{}) (interface{}, error) {
+			numbers, err := queryNumbers("sum", arguments[0])
+			if err != nil {
+				return nil, err
+			}
+			sum := 0.0
+			for _, number := range numbers {
+				sum += number
+			}
+			return queryNumberResult(sum), nil
+		}},
+		"to_array": {arguments: [][]queryType{anything}, call: func(arguments []interface{}) (interface{}, error) {
+			if items, ok := arguments[0].([]interface{}); ok {
+				return items, nil
+			}
+			return []interface{}{arguments[0]}, nil
+		}},
+		"to_number": {arguments: [][]queryType{anything}, call: func(arguments []interface{}) (interface{}, error) {
+			if _, ok := queryNumber(arguments[0]); ok {
+				return arguments[0], nil
+			}
+			text, ok := arguments[0].(string)
+			if !ok {
+				return nil, nil
+			}
+			if integer, err := strconv.Atoi(text); err == nil {
+				return integer, nil
+			}
+			if float, err := strconv.ParseFloat(text, 64); err == nil {
+				return float, nil
+			}
+			return nil, nil
+		}},
+		"to_string": {arguments: [][]queryType{anything}, call: func(arguments []interface{}) (interface{}, error) {
+			if text, ok := arguments[0].(string); ok {
+				return text, nil
+			}
+			encoded, err := json.Marshal(arguments[0])
+			if err != nil {
+				return nil, err
+			}
+			return string(encoded), nil
+		}},
+		"type": {arguments: [][]queryType{anything}, call: func(arguments []interface{}) (interface{}, error) {
+			return string(queryTypeOf(arguments[0])), nil
+		}},
+		"values": {arguments: [][]queryType{object}, call: func(arguments []interface{}) (interface{}, error) {
+			return sortedQueryValues(arguments[0].(map[string]interface{})), nil
+		}},
+	}
+}
+
+func callQueryFunction(name string, arguments []interface{}) (interface{}, error) {
+	function, ok := queryFunctions[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %s()", name)
+	}
+	if (!function.variadic && len(arguments) != len(function.arguments)) || (function.variadic && len(arguments) < len(function.arguments)) {
+		expected := strconv.Itoa(len(function.arguments))
+		if function.variadic {
+			expected = "at least " + expected
+		}
+		return nil, fmt.Errorf("%s() takes %s argument(s) but got %d", name, expected, len(arguments))
+	}
+	for index, argument := range arguments {
+		accepted := function.arguments[len(function.arguments)-1]
+		if index < len(function.arguments) {
+			accepted = function.arguments[index]
+		}
+		actual := queryTypeOf(argument)
+		valid := false
+		for _, expected := range accepted {
+			valid = valid || expected == actual || (expected == queryTypeAny && actual != queryTypeExpref)
+		}
+		if !valid {
+			names := []string{}
+			for _, expected := range accepted {
+				names = append(names, string(expected))
+			}
+			return nil, fmt.Errorf("invalid type for the %s argument of %s(): expected %s but got %s", humanize.Ordinal(index+1), name, strings.Join(names, " or "), actual)
+		}
+	}
+	return function.call(arguments)
+}
+
+func queryTypeOf(value interface{}) queryType {
+	if _, ok := queryNumber(value); ok {
+		return queryTypeNumber
+	}
+	switch value.(type) {
+	case nil:
+		return queryTypeNull
+	case string:
+		return queryTypeString
+	case bool:
+		return queryTypeBoolean
+	case []interface{}:
+		return queryTypeArray
+	case map[string]interface{}:
+		return queryTypeObject
+	case queryExpref:
+		return queryTypeExpref
+	default:
+		return queryTypeAny
+	}
+}
+
+func queryNumbers(name string, value interface{}) ([]float64, error) {
+	numbers := []float64{}
+	for _, item := range value.([]interface{}) {
+		number, ok := queryNumber(item)
+		if !ok {
+			return nil, fmt.Errorf("%s() expects an array of numbers but got an item of type %s", name, queryTypeOf(item))
+		}
+		numbers = append(numbers, number)
+	}
+	return numbers, nil
+}
+
+// queryKeys evaluates the optional expression against each item and checks that the resulting keys are either
+// all numbers or all strings, as required to sort or compare them.
+func queryKeys(name string, items []interface{}, expression *queryExpref) ([]interface{}, error) {
+	keys := make([]interface{}, 0, len(items))
+	var kind queryType
+	for _, item := range items {
+		key := item
+		if expression != nil {
+			var err error
+			if key, err = expression.node.evaluate(item); err != nil {
+				return nil, err
+			}
+		}
+		keyType := queryTypeOf(key)
+		if keyType != queryTypeNumber && keyType != queryTypeString {
+			return nil, fmt.Errorf("%s() expects numbers or strings to compare but got %s", name, keyType)
+		}
+		if kind != "" && keyType != kind {
+			return nil, fmt.Errorf("%s() expects values of the same type to compare but got %s and %s", name, kind, keyType)
+		}
+		kind = keyType
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func compareQueryKeys(left, right interface{}) int {
+	if leftNumber, ok := queryNumber(left); ok {
+		rightNumber, _ := queryNumber(right)
+		return compareFloats(leftNumber, rightNumber)
+	}
+	return strings.Compare(left.(string), right.(string))
+}
+
+func sortQueryValues(name string, items []interface{}, expression *queryExpref) (interface{}, error) {
+	keys, err := queryKeys(name, items, expression)
+	if err != nil {
+		return nil, err
+	}
+	indices := make([]int, len(items))
+	for index := range indices {
+		indices[index] = index
+	}
+	sort.SliceStable(indices, func(left, right int) bool {
+		return compareQueryKeys(keys[indices[left]], keys[indices[right]]) < 0
+	})
+	sorted := make([]interface{}, 0, len(items))
+	for _, index := range indices {
+		sorted = append(sorted, items[index])
+	}
+	return sorted, nil
+}
+
+func extremeQueryValue(name string, items []interface{}, expression *queryExpref, direction int) (interface{}, error) {
+	keys, err := queryKeys(name, items, expression)
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	extreme := 0
+	for index := range items {
+		if compareQueryKeys(keys[index], keys[extreme])*direction > 0 {
+			extreme = index
+		}
+	}
+	return items[extreme], nil
+}
+
+func sortedQueryKeys(dict map[string]interface{}) []string {
+	keys := make([]string, 0, len(dict))
+	for key := range dict {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,187 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// This file holds the escaping of strings for the target languages of the quote filters. Each function returns
+// a literal that evaluates to the input exactly, whatever the characters it holds.
+
+// shellSafe matches the strings the shlex.quote function of Python leaves unquoted.
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// quoteShell quotes a string for POSIX shells like the shlex.quote function of Python does: single quotes, in
+// which no character is special, are closed around each single quote of the input.
+func quoteShell(value string) string {
+	if value == "" {
+		return "''"
+	}
+	if shellSafe.MatchString(value) {
+		return value
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'"'"'`) + "'"
+}
+
+// quoteHCL returns a quoted HCL string, in which template sequences are escaped so that they are not
+// interpolated.
+func quoteHCL(value string) string {
+	builder := strings.Builder{}
+	builder.WriteByte('"')
+	for position, character := range value {
+		switch character {
+		case '\\':
+			builder.WriteString(`\\`)
+		case '"':
+			builder.WriteString(`\"`)
+		case '\n':
+			builder.WriteString(`\n`)
+		case '\r':
+			builder.WriteString(`\r`)
+		case '\t':
+			builder.WriteString(`\t`)
+		case '$', '%':
+			builder.WriteRune(character)
+			if strings.HasPrefix(value[position+1:], "{") {
+				builder.WriteRune(character)
+			}
+		default:
+			writeUnicodeEscape(&builder, character)
+		}
+	}
+	builder.WriteByte('"')
+	return builder.String()
+}
+
+// quoteJSON returns a JSON string literal. Characters with a special meaning in HTML are escaped as well, so
+// that the literal can be embedded in a script element.
+func quoteJSON(value string) string {
+	// Marshalling a string can not fail, invalid UTF-8 sequences being replaced
+	encoded, _ := json.Marshal(value)
+	return string(encoded)
+}
+
+// quoteYAML returns a YAML scalar loaded as the input string, plain when it is unambiguous in both YAML 1.1 and
+// YAML 1.2 as well as within flow collections, single quoted when it only holds printable characters and double
+// quoted otherwise.
+func quoteYAML(value string) string {
+	printable := true
+	for _, character := range value {
+		if !unicode.IsPrint(character) {
+			printable = false
+			break
+		}
+	}
+	switch {
+	case printable && isPlainYAML(value):
+		return value
+	case printable:
+		return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	}
+
+	builder := strings.Builder{}
+	builder.WriteByte('"')
+	for _, character := range value {
+		switch character {
+		case '\\':
+			builder.WriteString(`\\`)
+		case '"':
+			builder.WriteString(`\"`)
+		case 0:
+			builder.WriteString(`\0`)
+		case '\n':
+			builder.WriteString(`\n`)
+		case '\r':
+			builder.WriteString(`\r`)
+		case '\t':
+			builder.WriteString(`\t`)
+		default:
+			if !unicode.IsPrint(character) && character <= 0xFF {
+				builder.WriteString(fmt.Sprintf(`\x%02x`, character))
+				continue
+			}
+			writeUnicodeEscape(&builder, character)
+		}
+	}
+	builder.WriteByte('"')
+	return builder.String()
+}
+
+// isPlainYAML returns whether a string of printable characters can be written as a plain scalar.
+func isPlainYAML(value string) bool {
+	// Indicators may start plain scalars when followed by other characters, but not within flow collections, and
+	// some parsers read a question mark anywhere in a flow collection as the start of a complex key
+	if value == "" || strings.ContainsAny(value, ",[]{}?") || strings.ContainsAny(value[:1], "-?:#&*!|>'\"%@`") {
+		return false
+	}
+	// The value key of YAML 1.1, which some parsers still reject
+	if value == "=" {
+		return false
+	}
+	if yaml11Booleans.MatchString(value) || leadingZeroIntegers.MatchString(value) || sexagesimalNumbers.MatchString(value) {
+		return false
+	}
+	// Let the parser tell whether the scalar is loaded back as the same string, which rules out indicators,
+	// comments, keys, surrounding spaces and values resolved to other types
+	document := new(yaml.Node)
+	if err := yaml.Unmarshal([]byte(value), document); err != nil || len(document.Content) != 1 {
+		return false
+	}
+	node := document.Content[0]
+	return node.Kind == yaml.ScalarNode && node.Style == 0 && node.ShortTag() == "!!str" && node.Value == value
+}
+
+// sqlIdentifierQuotes maps the supported SQL dialects to the character their identifiers are quoted with.
+var sqlIdentifierQuotes = map[string]string{
+	"ansi":  `"`,
+	"mysql": "`",
+}
+
+// quoteSQLIdentifier quotes an identifier such as a table or column name, doubling the quote characters it
+// holds.
+func quoteSQLIdentifier(value, dialect string) (string, error) {
+	quote, ok := sqlIdentifierQuotes[dialect]
+	if !ok {
+		return "", fmt.Errorf("unsupported dialect %s, expected one of ansi, mysql", dialect)
+	}
+	switch {
+	case value == "":
+		return "", fmt.Errorf("identifiers can not be empty")
+	case strings.ContainsRune(value, 0):
+		return "", fmt.Errorf("identifiers can not hold NUL characters")
+	}
+	return quote + strings.ReplaceAll(value, quote, quote+quote) + quote, nil
+}
+
+// quotePowerShell returns a single quoted PowerShell string. PowerShell handles the typographic single quotes
+// as the ASCII one, so all of them are doubled.
+func quotePowerShell(value string) string {
+	builder := strings.Builder{}
+	builder.WriteByte('\'')
+	for _, character := range value {
+		builder.WriteRune(character)
+		switch character {
+		case '\'', '‘', '’', '‚', '‛':
+			builder.WriteRune(character)
+		}
+	}
+	builder.WriteByte('\'')
+	return builder.String()
+}
+
+// writeUnicodeEscape writes a printable character as is and any other one as a \u or \U escape sequence.
+func writeUnicodeEscape(builder *strings.Builder, character rune) {
+	switch {
+	case unicode.IsPrint(character):
+		builder.WriteRune(character)
+	case character <= 0xFFFF:
+		builder.WriteString(fmt.Sprintf(`\u%04x`, character))
+	default:
+		builder.WriteString(fmt.Sprintf(`\U%08x`, character))
+	}
+}
@@ -0,0 +1,125 @@
+package lib
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// maxCachedRegexes bounds the number of patterns kept by the regex cache, so that templates building patterns
+// out of their data can not grow it without limit.
+const maxCachedRegexes = 512
+
+var (
+	// regexCache holds the patterns compiled by the regex filters, keyed by their expression with
+	// flags, and shared by all renders as compiled patterns are safe for concurrent use.
+	regexCache       sync.Map
+	cachedRegexCount atomic.Int64
+)
+
+type regexFlags struct {
+	IgnoreCase bool
+	Multiline  bool
+}
+
+// compileRegex compiles the pattern with the given flags, reusing the patterns already compiled by any render.
+func compileRegex(pattern string, flags regexFlags) (*regexp.Regexp, error) {
+	expression := pattern
+	if flags.Multiline {
+		expression = "(?m)" + expression
+	}
+	if flags.IgnoreCase {
+		expression = "(?i)" + expression
+	}
+	if cached, ok := regexCache.Load(expression); ok {
+		return cached.(*regexp.Regexp), nil
+	}
+	compiled, err := regexp.Compile(expression)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile: %s: %s", pattern, err)
+	}
+	if cachedRegexCount.Load() < maxCachedRegexes {
+		if _, loaded := regexCache.LoadOrStore(expression, compiled); !loaded {
+			cachedRegexCount.Add(1)
+		}
+	}
+	return compiled, nil
+}
+
+// regexGroups returns the groups of a match as a dict when the pattern holds named groups, as a list when it
+// only holds unnamed groups, and the whole match otherwise. Groups that did not participate in the match are
+// None in dicts and empty strings in lists, like the groups method of Python matches given an empty default.
+func regexGroups(matcher *regexp.Regexp, input string, indices []int) interface{} {
+	group := func(index int) interface{} {
+		if indices[2*index] < 0 {
+			return nil
+		}
+		return input[indices[2*index]:indices[2*index+1]]
+	}
+	if matcher.NumSubexp() == 0 {
+		return group(0)
+	}
+	named := false
+	for _, name := range matcher.SubexpNames()[1:] {
+		named = named || name != ""
+	}
+	if named {
+		groups := make(map[string]interface{})
+		for index, name := range matcher.SubexpNames() {
+			if index > 0 && name != "" {
+				groups[name] = group(index)
+			}
+		}
+		return groups
+	}
+	groups := make([]interface{}, 0, matcher.NumSubexp())
+	for index := 1; index <= matcher.NumSubexp(); index++ {
+		if indices[2*index] < 0 {
+			groups = append(groups, "")
+			continue
+		}
+		groups = append(groups, group(index))
+	}
+	return groups
+}
+
+// convertReplacement turns the back references of Python replacement strings such as `\1` or `\g<name>` into
+// the `${1}` and `${name}` syntax of regexp.Expand, which can also be used directly.
+func convertReplacement(replacement string) string {
+	builder := strings.Builder{}
+	for position := 0; position < len(replacement); position++ {
+		character := replacement[position]
+		if character != '\\' || position+1 >= len(replacement) {
+			builder.WriteByte(character)
+			continue
+		}
+		next := replacement[position+1]
+		switch {
+		case next >= '0' && next <= '9':
+			end := position + 2
+			if end < len(replacement) && replacement[end] >= '0' && replacement[end] <= '9' {
+				end++
+			}
+			builder.WriteString("${" + replacement[position+1:end] + "}")
+			position = end - 1
+		case next == 'g' && strings.HasPrefix(replacement[position+2:], "<") && strings.Contains(replacement[position+2:], ">"):
+			end := position + 2 + strings.IndexByte(replacement[position+2:], '>')
+			builder.WriteString("${" + replacement[position+3:end] + "}")
+			position = end
+		case next == 'n':
+			builder.WriteByte('\n')
+			position++
+		case next == 't':
+			builder.WriteByte('\t')
+			position++
+		case next == '\\':
+			builder.WriteByte('\\')
+			position++
+		default:
+			builder.WriteByte(character)
+		}
+	}
+	return builder.String()
+}
@@ -25,10 +25,19 @@ import (
 type valuesFormat string
 
 const (
-	FormatJSON   valuesFormat = "json"
-	FormatYAML   valuesFormat = "yaml"
-	FormatTOML   valuesFormat = "toml"
-	FormatTFVars valuesFormat = "tfvars"
+	FormatJSON        valuesFormat = "json"
+	FormatYAML        valuesFormat = "yaml"
+	FormatTOML        valuesFormat = "toml"
+	FormatTFVars      valuesFormat = "tfvars"
+	FormatINI         valuesFormat = "ini"
+	FormatDotEnv      valuesFormat = "dotenv"
+	FormatProperties  valuesFormat = "properties"
+	FormatXML         valuesFormat = "xml"
+	FormatCSV         valuesFormat = "csv"
+	FormatTSV         valuesFormat = "tsv"
+	FormatNDJSON      valuesFormat = "ndjson"
+	FormatTFState     valuesFormat = "tfstate"
+	FormatEnvironment valuesFormat = "environment"
 )
 
 var (
@@ -37,21 +46,35 @@ var (
 		string(FormatYAML),
 		string(FormatTOML),
 		string(FormatTFVars),
+		string(FormatINI),
+		string(FormatDotEnv),
+		string(FormatProperties),
+		string(FormatXML),
+		string(FormatCSV),
+		string(FormatTSV),
+		string(FormatNDJSON),
+		string(FormatTFState),
+		string(FormatEnvironment),
 	}
 )
 
-func Render(ctx *Context) ([]byte, map[string]interface{}, error) {
+// Render executes the template against the merged context. Besides the result and the context, it returns the
+// files read through YAML tags, keyed by their path relative to the source directory and holding the sha256 of
+// their content as ExpandFiles does.
+func Render(ctx *Context) ([]byte, map[string]interface{}, map[string]string, error) {
 	channel := make(chan struct {
 		Result string
 		Values map[string]interface{}
+		Inputs map[string]string
 		Err    error
 	})
 	go func() {
 		result := struct {
 			Result string
 			Values map[string]interface{}
+			Inputs map[string]string
 			Err    error
-		}{}
+		}{Inputs: make(map[string]string)}
 		defer func() {
 			if err := recover(); err != nil {
 				result.Err = fmt.Errorf("a runtime error led the jinja engine to panic: %s", err)
@@ -65,62 +88,69 @@ func Render(ctx *Context) ([]byte, map[string]interface{}, error) {
 			return
 		}
 
-		result.Values, err = getValues(ctx.Values)
+		result.Values, err = getValues(ctx, result.Inputs)
 		if err != nil {
 			result.Err = fmt.Errorf("failed to parse values: %s", err)
 			return
 		}
 
+		result.Values, err = applyOverrides(result.Values, ctx.Overrides)
+		if err != nil {
+			result.Err = fmt.Errorf("failed to apply overrides: %s", err)
+			return
+		}
+
 		if err := validate(result.Values, ctx.Schemas); err != nil {
 			result.Err = fmt.Errorf("failed to validate context against schema: %s", err)
 			return
 		}
 
-		result.Result, result.Err = template.ExecuteToString(exec.NewContext(result.Values))
+		result.Result, result.Err = template.ExecuteToString(executionContext(ctx, result.Values))
 	}()
 	select {
 	case output := <-channel:
 		if output.Err != nil {
-			return nil, nil, fmt.Errorf("failed to execute template: %s", output.Err)
+			return nil, nil, nil, fmt.Errorf("failed to execute template: %s", output.Err)
 		}
-		return []byte(output.Result), output.Values, nil
+		return []byte(output.Result), output.Values, output.Inputs, nil
 	case <-time.After(ctx.Timeout):
-		return nil, nil, fmt.Errorf("rendering timed out after %s", ctx.Timeout.String())
+		return nil, nil, nil, fmt.Errorf("rendering timed out after %s", ctx.Timeout.String())
 	}
 }
 
-func getValues(values []Values) (map[string]interface{}, error) {
+func getValues(ctx *Context, inputs map[string]string) (map[string]interface{}, error) {
 	var mergedValues map[string]interface{}
-	for index, value := range values {
-		layer := make(map[string]interface{})
-		switch valuesFormat(strings.ToLower(value.Type)) {
-		case FormatJSON:
-			// Validate JSON context format before unmarshalling with YAML decoder to avoid casting ints to floats
-			// see https://stackoverflow.com/questions/71525600/golang-json-converts-int-to-float-what-can-i-do
-			if err := json.Unmarshal(value.Data, &map[string]interface{}{}); err != nil {
-				return nil, fmt.Errorf("failed to decode JSON context: %s", err)
-			}
-			if err := yaml.Unmarshal(value.Data, &layer); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal JSON context: %s", err)
-			}
-		case FormatYAML:
-			if err := yaml.Unmarshal(value.Data, &layer); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal YAML context: %s", err)
+	for index, value := range ctx.Values {
+		if value.Encrypted {
+			plaintext, err := decryptPGP(value.Data, ctx.Configuration.Decryption)
+			if err != nil {
+				if value.File != "" {
+					return nil, fmt.Errorf("%s: failed to decrypt %s values layer: %s", value.File, humanize.Ordinal(index+1), err)
+				}
+				return nil, fmt.Errorf("failed to decrypt %s values layer: %s", humanize.Ordinal(index+1), err)
 			}
-		case FormatTOML:
-			if err := toml.Unmarshal(value.Data, &layer); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal TOML context: %s", err)
+			value.Data = plaintext
+		}
+		decoded, err := decodeValues(ctx, value, inputs)
+		if err != nil {
+			if value.File != "" {
+				return nil, fmt.Errorf("%s: %s", value.File, err)
 			}
-		case FormatTFVars:
-			varsJson, err := tfvars_parser.Bytes([]byte(value.Data), "", tfvars_parser.Options{Simplify: true})
-			if err != nil {
-				return nil, fmt.Errorf("failed to unmarshal TFVars context: %s", err.Error())
+			return nil, fmt.Errorf("%s values layer: %s", humanize.Ordinal(index+1), err)
+		}
+		layer, ok := decoded.(map[string]interface{})
+		if value.Key != "" {
+			if layer, err = nestUnderPath(value.Key, decoded); err != nil {
+				return nil, fmt.Errorf("%s values layer can not be loaded under key %s: %s", humanize.Ordinal(index+1), value.Key, err)
 			}
-			if err := yaml.Unmarshal(varsJson, &layer); err != nil {
-				return nil, err
+		} else if !ok {
+			return nil, fmt.Errorf("%s values layer is not a dict and must be loaded under a key", humanize.Ordinal(index+1))
+		}
+
+		if value.Render {
+			if layer, err = renderLayer(ctx, mergedValues, layer); err != nil {
+				return nil, fmt.Errorf("failed to render %s values layer: %s", humanize.Ordinal(index+1), err)
 			}
-		default:
-			return nil, fmt.Errorf("provided context has an unsupported type: %v", value.Type)
 		}
 
 		if mergedValues == nil {
@@ -134,7 +164,138 @@ func getValues(values []Values) (map[string]interface{}, error) {
 	return mergedValues, nil
 }
 
+func decodeValues(ctx *Context, value Values, inputs map[string]string) (interface{}, error) {
+	layer := make(map[string]interface{})
+	switch valuesFormat(strings.ToLower(value.Type)) {
+	case FormatJSON:
+		// Validate JSON context format before unmarshalling with YAML decoder to avoid casting ints to floats
+		// see https://stackoverflow.com/questions/71525600/golang-json-converts-int-to-float-what-can-i-do
+		if err := json.Unmarshal(value.Data, new(interface{})); err != nil {
+			return nil, fmt.Errorf("failed to decode JSON context: %s", err)
+		}
+		if ctx.Configuration.StrictParsing {
+			if err := checkStrictYAML(value.Data); err != nil {
+				return nil, fmt.Errorf("failed to decode JSON context: %s", err)
+			}
+		}
+		decoded, err := decodeYAMLDocument(value.Data, layer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal JSON context: %s", err)
+		}
+		return decoded, nil
+	case FormatYAML:
+		if value.YAMLTags {
+			decoded, err := decodeTaggedYAML(value.Data, ctx.Source.Directory, value.File, ctx.Configuration.StrictParsing, inputs)
+			if err != nil {
+				return nil, fmt.Errorf("failed to unmarshal YAML context: %s", err)
+			}
+			return decoded, nil
+		}
+		if ctx.Configuration.StrictParsing {
+			if err := checkStrictYAML(value.Data); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal YAML context: %s", err)
+			}
+		}
+		decoded, err := decodeYAMLDocument(value.Data, layer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal YAML context: %s", err)
+		}
+		return decoded, nil
+	case FormatTOML:
+		if err := toml.Unmarshal(value.Data, &layer); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal TOML context: %s", err)
+		}
+	case FormatTFVars:
+		varsJson, err := tfvars_parser.Bytes([]byte(value.Data), "", tfvars_parser.Options{Simplify: true})
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal TFVars context: %s", err.Error())
+		}
+		if err := yaml.Unmarshal(varsJson, &layer); err != nil {
+			return nil, err
+		}
+	case FormatINI:
+		decoded, err := decodeINI(value.Data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal INI context: %s", err)
+		}
+		return decoded, nil
+	case FormatDotEnv:
+		decoded, err := decodeDotEnv(value.Data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal dotenv context: %s", err)
+		}
+		return decoded, nil
+	case FormatProperties:
+		decoded, err := decodeProperties(value.Data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal properties context: %s", err)
+		}
+		return decoded, nil
+	case FormatXML:
+		decoded, err := decodeXML(value.Data, xmlConvention{
+			AttributePrefix: defaultXMLAttributePrefix,
+			TextKey:         defaultXMLTextKey,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal XML context: %s", err)
+		}
+		return decoded, nil
+	case FormatCSV:
+		decoded, err := decodeDelimited(value.Data, ',', value.Tabular)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal CSV context: %s", err)
+		}
+		return decoded, nil
+	case FormatTSV:
+		decoded, err := decodeDelimited(value.Data, '\t', value.Tabular)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal TSV context: %s", err)
+		}
+		return decoded, nil
+	case FormatNDJSON:
+		decoded, err := decodeNDJSON(value.Data, value.Tabular)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal NDJSON context: %s", err)
+		}
+		return decoded, nil
+	case FormatEnvironment:
+		decoded, err := decodeEnvironment(value.Environment)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load environment context: %s", err)
+		}
+		return decoded, nil
+	case FormatTFState:
+		decoded, err := decodeTFState(value.Data, value.AllowSensitive)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal terraform state context: %s", err)
+		}
+		return decoded, nil
+	default:
+		return nil, fmt.Errorf("provided context has an unsupported type: %v", value.Type)
+	}
+	return layer, nil
+}
+
+// decodeYAMLDocument decodes a document into the given dictionary when possible, and falls back to any other
+// type of value such as a list or a scalar otherwise. Empty documents decode into the given empty dictionary.
+func decodeYAMLDocument(data []byte, layer map[string]interface{}) (interface{}, error) {
+	if err := yaml.Unmarshal(data, &layer); err == nil {
+		return layer, nil
+	}
+	var decoded interface{}
+	if err := yaml.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
+
 func parseTemplate(ctx *Context) (*exec.Template, error) {
+	return parseTemplateString(ctx, ctx.Source.Template)
+}
+
+// parseTemplateString parses the given template with the same configuration, filters, tests and globals as the
+// root template of the given context.
+func parseTemplateString(ctx *Context, template string) (*exec.Template, error) {
 	gonjaConfig := config.New()
 
 	gonjaConfig.BlockStartString = ctx.Configuration.Delimiters.BlockStart
@@ -160,12 +321,12 @@ func parseTemplate(ctx *Context) (*exec.Template, error) {
 	}
 
 	sha := sha256.New()
-	if _, err := sha.Write([]byte(ctx.Source.Template)); err != nil {
+	if _, err := sha.Write([]byte(template)); err != nil {
 		return nil, fmt.Errorf("failed to compute sha256 from root template")
 	}
 	rootID := fmt.Sprintf("root-%s", hex.EncodeToString(sha.Sum(nil)))
 
-	shiftedLoader, err := loaders.NewShiftedLoader(rootID, bytes.NewBufferString(ctx.Source.Template), fileSystemLoader)
+	shiftedLoader, err := loaders.NewShiftedLoader(rootID, bytes.NewBufferString(template), fileSystemLoader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create a shifted loader: %v", err)
 	}
@@ -0,0 +1,136 @@
+package lib
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/nikolalohinski/gonja/v2/exec"
+)
+
+// The set filters and tests handle lists as sets: elements are compared with reflect.DeepEqual over their simple
+// Go types like the distinct filter does, or over the value found at the path given with by, and the results
+// keep the order in which elements are first seen.
+
+type setElement struct {
+	value interface{}
+	key   interface{}
+}
+
+// setKey returns what an element is compared on: the element itself, or the value at a dot separated path of
+// attributes, keys or list indexes such as `metadata.name` when by is set.
+func setKey(element *exec.Value, by string) (interface{}, error) {
+	current := element
+	if by != "" {
+		for _, part := range strings.Split(by, ".") {
+			next, found := current.Get(part)
+			if index, err := strconv.Atoi(part); !found && err == nil {
+				next, found = current.GetItem(index)
+			}
+			if !found {
+				return nil, fmt.Errorf("%s has no attribute %s", element.String(), by)
+			}
+			current = next
+		}
+	}
+	casted := current.ToGoSimpleType(false)
+	if err, ok := casted.(error); ok {
+		return nil, err
+	}
+	return casted, nil
+}
+
+// setElements returns the distinct elements of a list, in order.
+func setElements(list *exec.Value, by string) ([]setElement, error) {
+	elements := []setElement{}
+	var err error
+	list.Iterate(func(_, _ int, item, _ *exec.Value) bool {
+		var key interface{}
+		if key, err = setKey(item, by); err != nil {
+			return false
+		}
+		if !containsSetKey(elements, key) {
+			var value interface{}
+			if !item.IsNil() {
+				value = item.Interface()
+			}
+			elements = append(elements, setElement{value: value, key: key})
+		}
+		return true
+	}, func() {})
+	return elements, err
+}
+
+func containsSetKey(elements []setElement, key interface{}) bool {
+	for _, element := range elements {
+		if reflect.DeepEqual(element.key, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// setDifference returns the elements of the left set that the right set does not hold.
+func setDifference(left, right []setElement) []interface{} {
+	values := []interface{}{}
+	for _, element := range left {
+		if !containsSetKey(right, element.key) {
+			values = append(values, element.value)
+		}
+	}
+	return values
+}
+
+func setIntersection(left, right []setElement) []interface{} {
+	values := []interface{}{}
+	for _, element := range left {
+		if containsSetKey(right, element.key) {
+			values = append(values, element.value)
+		}
+	}
+	return values
+}
+
+func setUnion(left, right []setElement) []interface{} {
+	values := make([]interface{}, 0, len(left))
+	for _, element := range left {
+		values = append(values, element.value)
+	}
+	return append(values, setDifference(right, left)...)
+}
+
+func setSymmetricDifference(left, right []setElement) []interface{} {
+	return append(setDifference(left, right), setDifference(right, left)...)
+}
+
+// setOperands takes the arguments of the set filters and tests, and returns the distinct elements of the input
+// and of the other list.
+func setOperands(in *exec.Value, params *exec.VarArgs) ([]setElement, []setElement, error) {
+	var (
+		other interface{}
+		by    string
+	)
+	if err := params.Take(
+		exec.PositionalArgument("other", nil, exec.AnyArgument(&other)),
+		exec.KeywordArgument("by", exec.AsValue(""), exec.StringArgument(&by)),
+	); err != nil {
+		return nil, nil, exec.ErrInvalidCall(err)
+	}
+	if !in.IsList() {
+		return nil, nil, exec.ErrInvalidCall(fmt.Errorf("%s is not a list", in.String()))
+	}
+	otherValue := exec.AsValue(other)
+	if !otherValue.IsList() {
+		return nil, nil, exec.ErrInvalidCall(fmt.Errorf("%s is not a list", otherValue.String()))
+	}
+	left, err := setElements(in, by)
+	if err != nil {
+		return nil, nil, err
+	}
+	right, err := setElements(otherValue, by)
+	if err != nil {
+		return nil, nil, err
+	}
+	return left, right, nil
+}
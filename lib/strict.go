@@ -0,0 +1,76 @@
+package lib
+
+import (
+	"fmt"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// Unquoted scalars that YAML 1.1 parsers load as booleans while YAML 1.2 ones load them as strings
+	yaml11Booleans = regexp.MustCompile(`^(y|Y|yes|Yes|YES|n|N|no|No|NO|on|On|ON|off|Off|OFF)$`)
+	// Unquoted integers with a leading zero, loaded as octal numbers in YAML 1.1 and as decimal ones elsewhere
+	leadingZeroIntegers = regexp.MustCompile(`^[-+]?0[0-9_]+$`)
+	// Unquoted base 60 numbers, such as 1:30, loaded as integers or floats in YAML 1.1
+	sexagesimalNumbers = regexp.MustCompile(`^[-+]?[0-9][0-9_]*(:[0-5]?[0-9])+(\.[0-9_]*)?$`)
+)
+
+// checkStrictYAML fails on duplicate keys, non string keys and unquoted values that YAML parsers interpret
+// differently depending on the version of the specification they implement.
+func checkStrictYAML(data []byte) error {
+	document := new(yaml.Node)
+	if err := yaml.Unmarshal(data, document); err != nil {
+		return err
+	}
+	return checkStrictNode(document)
+}
+
+func checkStrictNode(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.MappingNode:
+		defined := make(map[string]*yaml.Node)
+		for index := 0; index+1 < len(node.Content); index += 2 {
+			key, value := node.Content[index], node.Content[index+1]
+			if key.Kind != yaml.ScalarNode || (key.ShortTag() != "!!str" && key.ShortTag() != "!!merge") {
+				return fmt.Errorf("line %d, column %d: key %s is not a string", key.Line, key.Column, describeNode(key))
+			}
+			if key.ShortTag() == "!!str" {
+				if previous, ok := defined[key.Value]; ok {
+					return fmt.Errorf("line %d, column %d: key %q is already defined at line %d, column %d", key.Line, key.Column, key.Value, previous.Line, previous.Column)
+				}
+				defined[key.Value] = key
+			}
+			if err := checkStrictNode(value); err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		if node.Style != 0 {
+			return nil
+		}
+		if yaml11Booleans.MatchString(node.Value) || leadingZeroIntegers.MatchString(node.Value) || sexagesimalNumbers.MatchString(node.Value) {
+			return fmt.Errorf("line %d, column %d: unquoted value %s is ambiguous and must be quoted", node.Line, node.Column, node.Value)
+		}
+	default:
+		for _, child := range node.Content {
+			if err := checkStrictNode(child); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func describeNode(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "of type dict"
+	case yaml.SequenceNode:
+		return "of type list"
+	case yaml.AliasNode:
+		return "*" + node.Value
+	default:
+		return node.Value
+	}
+}
@@ -0,0 +1,220 @@
+package lib
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dustin/go-humanize"
+	json "github.com/json-iterator/go"
+	"gopkg.in/yaml.v3"
+)
+
+// TabularOptions controls how tabular data such as CSV, TSV or NDJSON is decoded and encoded.
+type TabularOptions struct {
+	Delimiter  string `json:"delimiter,omitempty"`
+	Header     bool   `json:"header"`
+	InferTypes bool   `json:"infer_types"`
+	IndexBy    string `json:"index_by,omitempty"`
+}
+
+var (
+	integerPattern = regexp.MustCompile(`^[-+]?[0-9]+$`)
+	floatPattern   = regexp.MustCompile(`^[-+]?([0-9]+\.[0-9]*|\.[0-9]+|[0-9]+)([eE][-+]?[0-9]+)?$`)
+	// Values such as zip codes or identifiers padded with leading zeros are kept as strings
+	leadingZeroPattern = regexp.MustCompile(`^[-+]?0[0-9]`)
+)
+
+func (o TabularOptions) delimiter(fallback rune) (rune, error) {
+	if o.Delimiter == "" {
+		return fallback, nil
+	}
+	delimiter, size := utf8.DecodeRuneInString(o.Delimiter)
+	if size != len(o.Delimiter) || delimiter == utf8.RuneError || delimiter == '"' || delimiter == '\r' || delimiter == '\n' {
+		return 0, fmt.Errorf("delimiter %q must be a single character other than a quote or a line break", o.Delimiter)
+	}
+	return delimiter, nil
+}
+
+// decodeDelimited parses delimiter separated values into a list of rows. When a header is expected, rows
+// are dictionaries keyed by column names, otherwise they are lists of cells. If IndexBy is set, a dictionary
+// keyed by the value of that column is returned instead of a list.
+func decodeDelimited(data []byte, fallback rune, options TabularOptions) (interface{}, error) {
+	delimiter, err := options.delimiter(fallback)
+	if err != nil {
+		return nil, err
+	}
+	if options.IndexBy != "" && !options.Header {
+		return nil, errors.New("indexing rows by a column requires a header")
+	}
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = delimiter
+	reader.LazyQuotes = true
+
+	var headers []string
+	if options.Header {
+		if headers, err = reader.Read(); err != nil {
+			return nil, fmt.Errorf("failed to read header row: %s", err)
+		}
+	}
+
+	rows := make([]interface{}, 0)
+	for {
+		cells, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read row: %s", err)
+		}
+		if !options.Header {
+			row := make([]interface{}, len(cells))
+			for index, cell := range cells {
+				row[index] = inferCell(cell, options.InferTypes)
+			}
+			rows = append(rows, row)
+			continue
+		}
+		row := make(map[string]interface{}, len(cells))
+		for index, cell := range cells {
+			row[headers[index]] = inferCell(cell, options.InferTypes)
+		}
+		rows = append(rows, row)
+	}
+	return indexRows(rows, options.IndexBy)
+}
+
+// decodeNDJSON parses newline delimited JSON documents into a list of values, or into a dictionary keyed by
+// the IndexBy field of each document when set.
+func decodeNDJSON(data []byte, options TabularOptions) (interface{}, error) {
+	rows := make([]interface{}, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), len(data)+1)
+	line := 0
+	for scanner.Scan() {
+		line++
+		content := bytes.TrimSpace(scanner.Bytes())
+		if len(content) == 0 {
+			continue
+		}
+		// Validate JSON format before unmarshalling with YAML decoder to avoid casting ints to floats
+		if err := json.Unmarshal(content, new(interface{})); err != nil {
+			return nil, fmt.Errorf("line %d: %s", line, err)
+		}
+		var row interface{}
+		if err := yaml.Unmarshal(content, &row); err != nil {
+			return nil, fmt.Errorf("line %d: %s", line, err)
+		}
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return indexRows(rows, options.IndexBy)
+}
+
+func indexRows(rows []interface{}, column string) (interface{}, error) {
+	if column == "" {
+		return rows, nil
+	}
+	indexed := make(map[string]interface{}, len(rows))
+	for index, row := range rows {
+		dict, ok := row.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s row is not a dict and can not be indexed by %s", humanize.Ordinal(index+1), column)
+		}
+		value, ok := dict[column]
+		if !ok {
+			return nil, fmt.Errorf("%s row has no %s column to be indexed by", humanize.Ordinal(index+1), column)
+		}
+		key, err := scalarToString(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s row can not be indexed by %s: %s", humanize.Ordinal(index+1), column, err)
+		}
+		if _, duplicated := indexed[key]; duplicated {
+			return nil, fmt.Errorf("%s row has a duplicated %s value: %s", humanize.Ordinal(index+1), column, key)
+		}
+		indexed[key] = dict
+	}
+	return indexed, nil
+}
+
+func inferCell(cell string, infer bool) interface{} {
+	if !infer {
+		return cell
+	}
+	switch lowered := strings.ToLower(cell); {
+	case lowered == "true":
+		return true
+	case lowered == "false":
+		return false
+	case leadingZeroPattern.MatchString(cell):
+		return cell
+	case integerPattern.MatchString(cell):
+		if integer, err := strconv.Atoi(cell); err == nil {
+			return integer
+		}
+	case floatPattern.MatchString(cell):
+		if float, err := strconv.ParseFloat(cell, 64); err == nil {
+			return float
+		}
+	}
+	return cell
+}
+
+// encodeDelimited serializes a list of dictionaries into delimiter separated values, with cells ordered
+// following the given columns. When no columns are given, the sorted union of all keys is used.
+func encodeDelimited(rows []interface{}, columns []string, fallback rune, options TabularOptions) (string, error) {
+	delimiter, err := options.delimiter(fallback)
+	if err != nil {
+		return "", err
+	}
+	dicts := make([]map[string]interface{}, len(rows))
+	for index, row := range rows {
+		dict, ok := row.(map[string]interface{})
+		if !ok {
+			return "", fmt.Errorf("%s row %v is not a dict", humanize.Ordinal(index+1), row)
+		}
+		dicts[index] = dict
+	}
+	if len(columns) == 0 {
+		union := make(map[string]interface{})
+		for _, dict := range dicts {
+			for key := range dict {
+				union[key] = nil
+			}
+		}
+		columns = sortedKeys(union)
+	}
+
+	buffer := bytes.NewBuffer(nil)
+	writer := csv.NewWriter(buffer)
+	writer.Comma = delimiter
+	if options.Header {
+		if err := writer.Write(columns); err != nil {
+			return "", err
+		}
+	}
+	for index, dict := range dicts {
+		record := make([]string, len(columns))
+		for position, column := range columns {
+			cell, err := scalarToString(dict[column])
+			if err != nil {
+				return "", fmt.Errorf("%s row, column %s: %s", humanize.Ordinal(index+1), column, err)
+			}
+			record[position] = cell
+		}
+		if err := writer.Write(record); err != nil {
+			return "", err
+		}
+	}
+	writer.Flush()
+	return buffer.String(), writer.Error()
+}
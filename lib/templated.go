@@ -0,0 +1,295 @@
+package lib
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"dario.cat/mergo"
+	json "github.com/json-iterator/go"
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+// maxRenderPasses bounds the number of times the string values of a templated layer are rendered while they
+// keep changing because they reference other templated values.
+const maxRenderPasses = 10
+
+// renderLayer renders every string value of the given layer as a template against the values merged so far
+// and the layer itself. Templates are rendered again against the previous pass' results until they stop
+// changing so that templated values can reference each other, and an error is returned when values reference
+// themselves or each other in a cycle.
+func renderLayer(ctx *Context, merged map[string]interface{}, layer map[string]interface{}) (map[string]interface{}, error) {
+	seen := map[string]bool{}
+	current := layer
+	changing := []string{}
+	for pass := 0; pass < maxRenderPasses; pass++ {
+		scope, ok := deepCopy(merged).(map[string]interface{})
+		if !ok || scope == nil {
+			scope = make(map[string]interface{})
+		}
+		if err := mergo.Merge(&scope, deepCopy(current), mergo.WithOverride, mergo.WithOverrideEmptySlice); err != nil {
+			return nil, err
+		}
+
+		rendered, err := renderStrings(ctx, layer, scope, "")
+		if err != nil {
+			return nil, err
+		}
+		next := rendered.(map[string]interface{})
+		if changing = changedPaths(layer, current, next, ""); len(changing) == 0 {
+			// A template rendering into itself can only be referencing itself
+			if unresolved := unresolvedPaths(ctx, layer, next, ""); len(unresolved) > 0 {
+				return nil, fmt.Errorf("cycle detected in templated values: %s", strings.Join(unresolved, ", "))
+			}
+			return next, nil
+		}
+		selfReferencing, err := selfReferencingPaths(ctx, layer, scope, scope, func(interface{}) {}, changing, "")
+		if err != nil {
+			return nil, err
+		}
+		if len(selfReferencing) > 0 {
+			return nil, fmt.Errorf("cycle detected in templated values: %s", strings.Join(selfReferencing, ", "))
+		}
+		state, err := json.Marshal(next)
+		if err != nil {
+			return nil, err
+		}
+		if seen[string(state)] {
+			return nil, fmt.Errorf("cycle detected in templated values: %s", strings.Join(changing, ", "))
+		}
+		seen[string(state)] = true
+		current = next
+	}
+	return nil, fmt.Errorf("templated values are still changing after %d rendering passes: %s", maxRenderPasses, strings.Join(changing, ", "))
+}
+
+func renderStrings(ctx *Context, value interface{}, scope map[string]interface{}, path string) (interface{}, error) {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		rendered := make(map[string]interface{}, len(typed))
+		for key, item := range typed {
+			result, err := renderStrings(ctx, item, scope, joinPath(path, key))
+			if err != nil {
+				return nil, err
+			}
+			rendered[key] = result
+		}
+		return rendered, nil
+	case []interface{}:
+		rendered := make([]interface{}, len(typed))
+		for index, item := range typed {
+			result, err := renderStrings(ctx, item, scope, fmt.Sprintf("%s[%d]", path, index))
+			if err != nil {
+				return nil, err
+			}
+			rendered[index] = result
+		}
+		return rendered, nil
+	case string:
+		if !isTemplated(ctx, typed) {
+			return typed, nil
+		}
+		template, err := parseTemplateString(ctx, typed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %s", path, err)
+		}
+		expression, ok := wholeExpression(ctx, typed)
+		if !ok {
+			result, err := template.ExecuteToString(executionContext(ctx, scope))
+			if err != nil {
+				return nil, fmt.Errorf("failed to render %s: %s", path, err)
+			}
+			return result, nil
+		}
+		// A value made of a single expression keeps the type of what it evaluates to, serialized to JSON by the
+		// template and decoded with the YAML decoder which keeps integers as such
+		delimiters := ctx.Configuration.Delimiters
+		if template, err = parseTemplateString(ctx, delimiters.VariableStart+" ("+expression+") | tojson "+delimiters.VariableEnd); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %s", path, err)
+		}
+		result, err := template.ExecuteToString(executionContext(ctx, scope))
+		if err != nil {
+			return nil, fmt.Errorf("failed to render %s: %s", path, err)
+		}
+		var decoded interface{}
+		if err := yaml.Unmarshal([]byte(result), &decoded); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %s", path, err)
+		}
+		return decoded, nil
+	default:
+		return value, nil
+	}
+}
+
+// wholeExpression returns the expression of a string made of a single variable block and nothing else, such as
+// `{{ replicas + 1 }}`.
+func wholeExpression(ctx *Context, value string) (string, bool) {
+	delimiters := ctx.Configuration.Delimiters
+	if len(value) < len(delimiters.VariableStart)+len(delimiters.VariableEnd) || !strings.HasPrefix(value, delimiters.VariableStart) || !strings.HasSuffix(value, delimiters.VariableEnd) {
+		return "", false
+	}
+	expression := value[len(delimiters.VariableStart) : len(value)-len(delimiters.VariableEnd)]
+	for _, delimiter := range []string{delimiters.VariableStart, delimiters.VariableEnd, delimiters.BlockStart, delimiters.CommentStart} {
+		if strings.Contains(expression, delimiter) {
+			return "", false
+		}
+	}
+	// Whitespace control markers have no effect on a value holding nothing else
+	expression = strings.TrimSuffix(strings.TrimLeft(expression, "-+"), "-")
+	if strings.TrimSpace(expression) == "" {
+		return "", false
+	}
+	return expression, true
+}
+
+// selfReferenceMarker replaces the value of a templated string in its own scope to find out whether it renders
+// differently, which means that it references itself.
+const selfReferenceMarker = "\x00self-reference\x00"
+
+// selfReferencingPaths lists the paths, among the given changing ones, of the templated strings whose rendering
+// depends on their own value. Their value is replaced in the scope through set while they are rendered again.
+func selfReferencingPaths(ctx *Context, value, scoped interface{}, scope map[string]interface{}, set func(interface{}), changing []string, path string) ([]string, error) {
+	paths := []string{}
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		scopedMap, ok := scoped.(map[string]interface{})
+		if !ok {
+			return paths, nil
+		}
+		for key, item := range typed {
+			key := key
+			found, err := selfReferencingPaths(ctx, item, scopedMap[key], scope, func(replacement interface{}) { scopedMap[key] = replacement }, changing, joinPath(path, key))
+			if err != nil {
+				return nil, err
+			}
+			paths = append(paths, found...)
+		}
+	case []interface{}:
+		scopedList, ok := scoped.([]interface{})
+		if !ok || len(scopedList) != len(typed) {
+			return paths, nil
+		}
+		for index, item := range typed {
+			index := index
+			found, err := selfReferencingPaths(ctx, item, scopedList[index], scope, func(replacement interface{}) { scopedList[index] = replacement }, changing, fmt.Sprintf("%s[%d]", path, index))
+			if err != nil {
+				return nil, err
+			}
+			paths = append(paths, found...)
+		}
+	case string:
+		if !slices.Contains(changing, path) {
+			return paths, nil
+		}
+		template, err := parseTemplateString(ctx, typed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %s", path, err)
+		}
+		rendered, err := template.ExecuteToString(executionContext(ctx, scope))
+		if err != nil {
+			return nil, fmt.Errorf("failed to render %s: %s", path, err)
+		}
+		set(selfReferenceMarker)
+		replaced, err := template.ExecuteToString(executionContext(ctx, scope))
+		set(scoped)
+		if err != nil || replaced != rendered {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+func isTemplated(ctx *Context, value string) bool {
+	return strings.Contains(value, ctx.Configuration.Delimiters.VariableStart) || strings.Contains(value, ctx.Configuration.Delimiters.BlockStart)
+}
+
+// changedPaths lists the paths of the strings of the original layer whose rendering differs between two values
+// sharing its structure. Rendered values can be of any type, and are compared as a whole.
+func changedPaths(original, before, after interface{}, path string) []string {
+	paths := []string{}
+	switch typed := original.(type) {
+	case map[string]interface{}:
+		beforeMap, _ := before.(map[string]interface{})
+		afterMap, _ := after.(map[string]interface{})
+		for key, item := range typed {
+			paths = append(paths, changedPaths(item, beforeMap[key], afterMap[key], joinPath(path, key))...)
+		}
+	case []interface{}:
+		beforeList, _ := before.([]interface{})
+		afterList, _ := after.([]interface{})
+		for index, item := range typed {
+			if index >= len(beforeList) || index >= len(afterList) {
+				break
+			}
+			paths = append(paths, changedPaths(item, beforeList[index], afterList[index], fmt.Sprintf("%s[%d]", path, index))...)
+		}
+	case string:
+		if !reflect.DeepEqual(before, after) {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// unresolvedPaths lists the paths of the templated strings of the original layer that render into themselves.
+func unresolvedPaths(ctx *Context, original, rendered interface{}, path string) []string {
+	changed := changedPaths(original, original, rendered, path)
+	paths := []string{}
+	for _, templated := range templatedPaths(ctx, original, path) {
+		if !slices.Contains(changed, templated) {
+			paths = append(paths, templated)
+		}
+	}
+	return paths
+}
+
+func templatedPaths(ctx *Context, value interface{}, path string) []string {
+	paths := []string{}
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for key, item := range typed {
+			paths = append(paths, templatedPaths(ctx, item, joinPath(path, key))...)
+		}
+	case []interface{}:
+		for index, item := range typed {
+			paths = append(paths, templatedPaths(ctx, item, fmt.Sprintf("%s[%d]", path, index))...)
+		}
+	case string:
+		if isTemplated(ctx, typed) {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func joinPath(path, key string) string {
+	escaped := pathSegment{Key: key}.String()
+	if path == "" {
+		return escaped
+	}
+	return path + "." + escaped
+}
+
+func deepCopy(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(typed))
+		for key, item := range typed {
+			copied[key] = deepCopy(item)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(typed))
+		for index, item := range typed {
+			copied[index] = deepCopy(item)
+		}
+		return copied
+	default:
+		return value
+	}
+}
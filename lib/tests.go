@@ -3,14 +3,25 @@ package lib
 import (
 	"errors"
 	"fmt"
+	"net/netip"
 	"regexp"
 
+	"github.com/hashicorp/go-version"
 	"github.com/nikolalohinski/gonja/v2/exec"
 )
 
 var Tests = exec.NewTestSet(map[string]exec.TestFunction{
-	"empty": testEmpty,
-	"match": testMatch,
+	"cidr_contains": testCIDRContains,
+	"empty":         testEmpty,
+	"has_path":      testHasPath,
+	"ip":            testIP,
+	"ipv4":          testIPv4,
+	"ipv6":          testIPv6,
+	"match":         testMatch,
+	"query":         testQuery,
+	"subset":        testSubset,
+	"superset":      testSuperset,
+	"version":       testVersion,
 })
 
 func testEmpty(ctx *exec.Context, in *exec.Value, params *exec.VarArgs) (bool, error) {
@@ -46,3 +57,148 @@ func testMatch(ctx *exec.Context, in *exec.Value, params *exec.VarArgs) (bool, e
 
 	return matcher.MatchString(in.String()), nil
 }
+
+func testQuery(ctx *exec.Context, in *exec.Value, params *exec.VarArgs) (bool, error) {
+	if in.IsError() {
+		return false, errors.New(in.Error())
+	}
+	var (
+		expression string
+	)
+	if err := params.Take(
+		exec.PositionalArgument("expression", nil, exec.StringArgument(&expression)),
+	); err != nil {
+		return false, exec.ErrInvalidCall(err)
+	}
+	node, err := parseQuery(expression)
+	if err != nil {
+		return false, exec.ErrInvalidCall(err)
+	}
+	result, err := node.evaluateValue(in)
+	if err != nil {
+		return false, err
+	}
+	return isQueryTruthy(result), nil
+}
+
+func testHasPath(ctx *exec.Context, in *exec.Value, params *exec.VarArgs) (bool, error) {
+	if in.IsError() {
+		return false, errors.New(in.Error())
+	}
+	var (
+		path string
+	)
+	if err := params.Take(
+		exec.PositionalArgument("path", nil, exec.StringArgument(&path)),
+	); err != nil {
+		return false, exec.ErrInvalidCall(err)
+	}
+	segments, err := parseAnyPath(path)
+	if err != nil {
+		return false, exec.ErrInvalidCall(err)
+	}
+	_, ok, err := lookupPath(in, segments)
+	return ok, err
+}
+
+func testCIDRContains(ctx *exec.Context, in *exec.Value, params *exec.VarArgs) (bool, error) {
+	if in.IsError() {
+		return false, errors.New(in.Error())
+	}
+	var (
+		other string
+	)
+	if err := params.Take(
+		exec.PositionalArgument("other", nil, exec.StringArgument(&other)),
+	); err != nil {
+		return false, exec.ErrInvalidCall(err)
+	}
+	if !in.IsString() {
+		return false, exec.ErrInvalidCall(fmt.Errorf("%s is not a string", in.String()))
+	}
+	outer, err := parseCIDR(in.String())
+	if err != nil {
+		return false, exec.ErrInvalidCall(err)
+	}
+	inner, err := parseNetwork(other)
+	if err != nil {
+		return false, exec.ErrInvalidCall(err)
+	}
+	return networkContains(outer, inner), nil
+}
+
+func testIP(ctx *exec.Context, in *exec.Value, params *exec.VarArgs) (bool, error) {
+	return testIPVersion(in, params, func(prefix netip.Prefix) bool { return true })
+}
+
+func testIPv4(ctx *exec.Context, in *exec.Value, params *exec.VarArgs) (bool, error) {
+	return testIPVersion(in, params, func(prefix netip.Prefix) bool { return prefix.Addr().Is4() })
+}
+
+func testIPv6(ctx *exec.Context, in *exec.Value, params *exec.VarArgs) (bool, error) {
+	return testIPVersion(in, params, func(prefix netip.Prefix) bool { return prefix.Addr().Is6() })
+}
+
+// testIPVersion returns whether the input is a valid IP address or network in CIDR notation matching the given
+// condition. Values that are not strings are not valid.
+func testIPVersion(in *exec.Value, params *exec.VarArgs, condition func(netip.Prefix) bool) (bool, error) {
+	if in.IsError() {
+		return false, errors.New(in.Error())
+	}
+	if err := params.Take(); err != nil {
+		return false, exec.ErrInvalidCall(err)
+	}
+	if !in.IsString() {
+		return false, nil
+	}
+	prefix, err := parseNetwork(in.String())
+	if err != nil {
+		return false, nil
+	}
+	return condition(prefix), nil
+}
+
+func testVersion(ctx *exec.Context, in *exec.Value, params *exec.VarArgs) (bool, error) {
+	if in.IsError() {
+		return false, errors.New(in.Error())
+	}
+	var (
+		constraint string
+	)
+	if err := params.Take(
+		exec.PositionalArgument("constraint", nil, exec.StringArgument(&constraint)),
+	); err != nil {
+		return false, exec.ErrInvalidCall(err)
+	}
+	parsed, err := parseVersion(in)
+	if err != nil {
+		return false, exec.ErrInvalidCall(err)
+	}
+	constraints, err := version.NewConstraint(constraint)
+	if err != nil {
+		return false, exec.ErrInvalidCall(fmt.Errorf("%s is not a valid version constraint", constraint))
+	}
+	return constraints.Check(parsed), nil
+}
+
+func testSubset(ctx *exec.Context, in *exec.Value, params *exec.VarArgs) (bool, error) {
+	if in.IsError() {
+		return false, errors.New(in.Error())
+	}
+	left, right, err := setOperands(in, params)
+	if err != nil {
+		return false, err
+	}
+	return len(setDifference(left, right)) == 0, nil
+}
+
+func testSuperset(ctx *exec.Context, in *exec.Value, params *exec.VarArgs) (bool, error) {
+	if in.IsError() {
+		return false, errors.New(in.Error())
+	}
+	left, right, err := setOperands(in, params)
+	if err != nil {
+		return false, err
+	}
+	return len(setDifference(right, left)) == 0, nil
+}
@@ -0,0 +1,172 @@
+package lib
+
+import (
+	"fmt"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	json "github.com/json-iterator/go"
+	"gopkg.in/yaml.v3"
+)
+
+const sensitiveValuePlaceholder = "(sensitive value)"
+
+// decodeTFState parses the JSON output of `terraform show -json` for either a state or a plan into a
+// template friendly dictionary holding:
+//   - outputs: a dictionary of output values ;
+//   - resources: a flat list of resource instances across all modules with their address, module, mode,
+//     type, name, index, provider and values, plus the planned actions when decoding a plan ;
+//   - modules: the sorted list of module addresses holding resources ;
+//   - changes: the list of planned resource changes with their actions, only when decoding a plan.
+//
+// Sensitive values are replaced by a placeholder unless allowSensitive is set.
+func decodeTFState(data []byte, allowSensitive bool) (map[string]interface{}, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	_, hasPlannedValues := fields["planned_values"]
+	_, hasResourceChanges := fields["resource_changes"]
+
+	result := map[string]interface{}{}
+	var values *tfjson.StateValues
+	actions := map[string]interface{}{}
+	if hasPlannedValues || hasResourceChanges {
+		plan := new(tfjson.Plan)
+		if err := plan.UnmarshalJSON(data); err != nil {
+			return nil, fmt.Errorf("invalid plan: %s", err)
+		}
+		result["terraform_version"] = plan.TerraformVersion
+		values = plan.PlannedValues
+		changes := make([]interface{}, 0, len(plan.ResourceChanges))
+		for _, change := range plan.ResourceChanges {
+			planned := []interface{}{}
+			if change.Change != nil {
+				for _, action := range change.Change.Actions {
+					planned = append(planned, string(action))
+				}
+			}
+			actions[change.Address] = planned
+			changes = append(changes, map[string]interface{}{
+				"address":  change.Address,
+				"module":   change.ModuleAddress,
+				"mode":     string(change.Mode),
+				"type":     change.Type,
+				"name":     change.Name,
+				"index":    change.Index,
+				"provider": change.ProviderName,
+				"actions":  planned,
+			})
+		}
+		result["changes"] = changes
+	} else {
+		state := new(tfjson.State)
+		state.UseJSONNumber(true)
+		if err := state.UnmarshalJSON(data); err != nil {
+			return nil, fmt.Errorf("invalid state: %s", err)
+		}
+		result["terraform_version"] = state.TerraformVersion
+		values = state.Values
+	}
+
+	outputs := map[string]interface{}{}
+	resources := []interface{}{}
+	modules := map[string]interface{}{}
+	if values != nil {
+		for name, output := range values.Outputs {
+			if output.Sensitive && !allowSensitive {
+				outputs[name] = sensitiveValuePlaceholder
+				continue
+			}
+			outputs[name] = output.Value
+		}
+		var err error
+		if resources, err = flattenStateModule(values.RootModule, resources, modules, actions, allowSensitive); err != nil {
+			return nil, err
+		}
+	}
+	result["outputs"] = outputs
+	result["resources"] = resources
+	result["modules"] = sortedKeys(modules)
+
+	// Round trip through JSON and YAML to get rid of terraform-json types and avoid casting ints to floats
+	serialized, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	decoded := make(map[string]interface{})
+	if err := yaml.Unmarshal(serialized, &decoded); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
+
+func flattenStateModule(module *tfjson.StateModule, resources []interface{}, modules, actions map[string]interface{}, allowSensitive bool) ([]interface{}, error) {
+	if module == nil {
+		return resources, nil
+	}
+	if module.Address != "" && len(module.Resources) > 0 {
+		modules[module.Address] = nil
+	}
+	for _, resource := range module.Resources {
+		var values interface{} = resource.AttributeValues
+		if !allowSensitive && len(resource.SensitiveValues) > 0 {
+			var sensitive interface{}
+			if err := json.Unmarshal(resource.SensitiveValues, &sensitive); err != nil {
+				return nil, fmt.Errorf("invalid sensitive values for %s: %s", resource.Address, err)
+			}
+			values = redactSensitive(values, sensitive)
+		}
+		flattened := map[string]interface{}{
+			"address":  resource.Address,
+			"module":   module.Address,
+			"mode":     string(resource.Mode),
+			"type":     resource.Type,
+			"name":     resource.Name,
+			"index":    resource.Index,
+			"provider": resource.ProviderName,
+			"values":   values,
+		}
+		if planned, ok := actions[resource.Address]; ok {
+			flattened["actions"] = planned
+		}
+		resources = append(resources, flattened)
+	}
+	for _, child := range module.ChildModules {
+		var err error
+		if resources, err = flattenStateModule(child, resources, modules, actions, allowSensitive); err != nil {
+			return nil, err
+		}
+	}
+	return resources, nil
+}
+
+// redactSensitive walks the given value alongside its sensitivity mask as found in the `sensitive_values`
+// field of terraform's JSON output, where a true boolean marks the matching value as sensitive.
+func redactSensitive(value interface{}, sensitive interface{}) interface{} {
+	switch mask := sensitive.(type) {
+	case bool:
+		if mask {
+			return sensitiveValuePlaceholder
+		}
+	case map[string]interface{}:
+		if dict, ok := value.(map[string]interface{}); ok {
+			redacted := make(map[string]interface{}, len(dict))
+			for key, item := range dict {
+				redacted[key] = redactSensitive(item, mask[key])
+			}
+			return redacted
+		}
+	case []interface{}:
+		if list, ok := value.([]interface{}); ok {
+			redacted := make([]interface{}, len(list))
+			for index, item := range list {
+				if index < len(mask) {
+					item = redactSensitive(item, mask[index])
+				}
+				redacted[index] = item
+			}
+			return redacted
+		}
+	}
+	return value
+}
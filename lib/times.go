@@ -0,0 +1,326 @@
+package lib
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nikolalohinski/gonja/v2/exec"
+)
+
+// clockKey is the name under which the time of the current render is stored in the context of each render. It
+// is not a valid identifier so that templates can neither read nor shadow it.
+const clockKey = "render clock"
+
+// executionContext returns the context to execute a template against, holding the given values and the time
+// returned by the now global, which is frozen when the configuration says so.
+func executionContext(ctx *Context, values map[string]interface{}) *exec.Context {
+	clock := ctx.Configuration.FrozenTime
+	if clock.IsZero() {
+		clock = time.Now()
+	}
+	context := exec.EmptyContext().Update(exec.NewContext(values))
+	context.Set(clockKey, clock)
+	return context
+}
+
+// currentTime returns the time of the current render, or the current time when rendering outside of Render.
+func currentTime(e *exec.Evaluator) time.Time {
+	if e != nil && e.Environment != nil && e.Environment.Context != nil {
+		if clock, ok := e.Environment.Context.Get(clockKey); ok {
+			if typed, ok := clock.(time.Time); ok {
+				return typed
+			}
+		}
+	}
+	return time.Now()
+}
+
+// parseTimestamp parses a timestamp held by an RFC 3339 string such as `2024-01-02T15:04:05Z`, or by a number
+// of seconds since the Unix epoch.
+func parseTimestamp(value *exec.Value) (time.Time, error) {
+	switch {
+	case value.IsInteger():
+		return time.Unix(int64(value.Integer()), 0).UTC(), nil
+	case value.IsFloat():
+		seconds := value.Float()
+		return time.Unix(0, int64(seconds*float64(time.Second))).UTC(), nil
+	case value.IsString():
+		parsed, err := time.Parse(time.RFC3339, value.String())
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%s is not a valid RFC 3339 timestamp", value.String())
+		}
+		return parsed, nil
+	default:
+		return time.Time{}, exec.ErrInvalidCall(fmt.Errorf("%s is neither a string nor a number", value.String()))
+	}
+}
+
+// formatTimestamp formats a time as an RFC 3339 string, like the timestamp function of Terraform does.
+func formatTimestamp(value time.Time) string {
+	return value.Format(time.RFC3339)
+}
+
+// parseDuration parses a duration such as `1h30m` with the syntax of the timeadd function of Terraform.
+func parseDuration(value string) (time.Duration, error) {
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s is not a valid duration", value)
+	}
+	return duration, nil
+}
+
+// strftime formats a time with the format codes of the strftime method of Python, using the C locale.
+func strftime(value time.Time, format string) (string, error) {
+	builder := strings.Builder{}
+	for position := 0; position < len(format); position++ {
+		if format[position] != '%' {
+			builder.WriteByte(format[position])
+			continue
+		}
+		if position+1 >= len(format) {
+			return "", exec.ErrInvalidCall(fmt.Errorf("format %s ends with an incomplete directive", format))
+		}
+		position++
+		switch directive := format[position]; directive {
+		case 'a':
+			builder.WriteString(value.Format("Mon"))
+		case 'A':
+			builder.WriteString(value.Format("Monday"))
+		case 'w':
+			builder.WriteString(strconv.Itoa(int(value.Weekday())))
+		case 'u':
+			builder.WriteString(strconv.Itoa((int(value.Weekday())+6)%7 + 1))
+		case 'd':
+			builder.WriteString(value.Format("02"))
+		case 'b':
+			builder.WriteString(value.Format("Jan"))
+		case 'B':
+			builder.WriteString(value.Format("January"))
+		case 'm':
+			builder.WriteString(value.Format("01"))
+		case 'y':
+			builder.WriteString(value.Format("06"))
+		case 'Y':
+			builder.WriteString(fmt.Sprintf("%04d", value.Year()))
+		case 'H':
+			builder.WriteString(value.Format("15"))
+		case 'I':
+			builder.WriteString(value.Format("03"))
+		case 'p':
+			builder.WriteString(value.Format("PM"))
+		case 'M':
+			builder.WriteString(value.Format("04"))
+		case 'S':
+			builder.WriteString(value.Format("05"))
+		case 'f':
+			builder.WriteString(fmt.Sprintf("%06d", value.Nanosecond()/1000))
+		case 'z':
+			builder.WriteString(value.Format("-0700"))
+		case 'Z':
+			builder.WriteString(value.Format("MST"))
+		case 'j':
+			builder.WriteString(fmt.Sprintf("%03d", value.YearDay()))
+		case 'U':
+			builder.WriteString(fmt.Sprintf("%02d", (value.YearDay()+6-int(value.Weekday()))/7))
+		case 'W':
+			builder.WriteString(fmt.Sprintf("%02d", (value.YearDay()+6-(int(value.Weekday())+6)%7)/7))
+		case 'G':
+			year, _ := value.ISOWeek()
+			builder.WriteString(fmt.Sprintf("%04d", year))
+		case 'V':
+			_, week := value.ISOWeek()
+			builder.WriteString(fmt.Sprintf("%02d", week))
+		case 'c':
+			builder.WriteString(value.Format("Mon Jan _2 15:04:05 2006"))
+		case 'x':
+			builder.WriteString(value.Format("01/02/06"))
+		case 'X':
+			builder.WriteString(value.Format("15:04:05"))
+		case '%':
+			builder.WriteByte('%')
+		default:
+			return "", exec.ErrInvalidCall(fmt.Errorf("unsupported directive %%%c in format %s", directive, format))
+		}
+	}
+	return builder.String(), nil
+}
+
+// timeScanner consumes the fields of a time from its input.
+type timeScanner struct {
+	input string
+}
+
+// number consumes up to the given number of digits.
+func (s *timeScanner) number(digits int) (int, bool) {
+	length := 0
+	for length < digits && length < len(s.input) && s.input[length] >= '0' && s.input[length] <= '9' {
+		length++
+	}
+	if length == 0 {
+		return 0, false
+	}
+	parsed, _ := strconv.Atoi(s.input[:length])
+	s.input = s.input[length:]
+	return parsed, true
+}
+
+// name consumes the first of the given names the input starts with, regardless of case, and returns its index.
+func (s *timeScanner) name(names ...string) (int, bool) {
+	for index, candidate := range names {
+		if len(s.input) >= len(candidate) && strings.EqualFold(s.input[:len(candidate)], candidate) {
+			s.input = s.input[len(candidate):]
+			return index, true
+		}
+	}
+	return 0, false
+}
+
+// literal consumes the given prefix.
+func (s *timeScanner) literal(prefix string) bool {
+	if !strings.HasPrefix(s.input, prefix) {
+		return false
+	}
+	s.input = s.input[len(prefix):]
+	return true
+}
+
+var (
+	monthNames      = []string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"}
+	shortMonthNames = []string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"}
+	dayNames        = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+	shortDayNames   = []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
+)
+
+// strptime parses a time with the format codes of the strptime method of Python, using the C locale. Fields
+// missing from the format default to the first of January 1900 at midnight, in UTC unless %z is used.
+func strptime(value, format string) (time.Time, error) {
+	var (
+		year, month, day      = 1900, 1, 1
+		hour, minute, second  = 0, 0, 0
+		nanosecond, yearDay   = 0, 0
+		afternoon, twelveHour = false, false
+		location              = time.UTC
+	)
+	invalid := fmt.Errorf("%s does not match format %s", value, format)
+	scanner := &timeScanner{input: value}
+	for position := 0; position < len(format); position++ {
+		if format[position] != '%' {
+			// As in Python, a space in the format matches any amount of whitespace
+			if format[position] == ' ' {
+				scanner.input = strings.TrimLeft(scanner.input, " \t\n")
+				continue
+			}
+			if !scanner.literal(format[position : position+1]) {
+				return time.Time{}, invalid
+			}
+			continue
+		}
+		if position+1 >= len(format) {
+			return time.Time{}, exec.ErrInvalidCall(fmt.Errorf("format %s ends with an incomplete directive", format))
+		}
+		position++
+		ok := true
+		switch directive := format[position]; directive {
+		case 'Y':
+			year, ok = scanner.number(4)
+		case 'y':
+			year, ok = scanner.number(2)
+			// Follow the POSIX convention used by Python: 69 to 99 are in the 1900s, 0 to 68 in the 2000s
+			if year < 69 {
+				year += 2000
+			} else {
+				year += 1900
+			}
+		case 'm':
+			month, ok = scanner.number(2)
+		case 'd':
+			day, ok = scanner.number(2)
+		case 'H':
+			hour, ok = scanner.number(2)
+		case 'I':
+			hour, ok = scanner.number(2)
+			twelveHour = true
+		case 'M':
+			minute, ok = scanner.number(2)
+		case 'S':
+			second, ok = scanner.number(2)
+		case 'j':
+			yearDay, ok = scanner.number(3)
+		case 'f':
+			length := len(scanner.input)
+			nanosecond, ok = scanner.number(6)
+			for digits := length - len(scanner.input); digits < 9; digits++ {
+				nanosecond *= 10
+			}
+		case 'p':
+			var index int
+			index, ok = scanner.name("AM", "PM")
+			afternoon = index == 1
+		case 'B', 'b':
+			var index int
+			if index, ok = scanner.name(monthNames...); !ok {
+				index, ok = scanner.name(shortMonthNames...)
+			}
+			month = index + 1
+		case 'A', 'a':
+			if _, ok = scanner.name(dayNames...); !ok {
+				_, ok = scanner.name(shortDayNames...)
+			}
+		case 'z':
+			if scanner.literal("Z") {
+				break
+			}
+			sign := 1
+			if scanner.literal("-") {
+				sign = -1
+			} else if !scanner.literal("+") {
+				return time.Time{}, invalid
+			}
+			var hours, minutes int
+			if hours, ok = scanner.number(2); !ok {
+				break
+			}
+			scanner.literal(":")
+			if minutes, ok = scanner.number(2); !ok {
+				break
+			}
+			location = time.FixedZone("", sign*(hours*3600+minutes*60))
+		case 'Z':
+			if _, ok = scanner.name("UTC", "GMT"); !ok {
+				ok = scanner.literal("Z")
+			}
+		case '%':
+			ok = scanner.literal("%")
+		default:
+			return time.Time{}, exec.ErrInvalidCall(fmt.Errorf("unsupported directive %%%c in format %s", directive, format))
+		}
+		if !ok {
+			return time.Time{}, invalid
+		}
+	}
+	if scanner.input != "" {
+		return time.Time{}, fmt.Errorf("%s does not match format %s: unconverted data remains: %s", value, format, scanner.input)
+	}
+	if twelveHour {
+		if hour < 1 || hour > 12 {
+			return time.Time{}, invalid
+		}
+		hour %= 12
+		if afternoon {
+			hour += 12
+		}
+	}
+	if month < 1 || month > 12 || day < 1 || day > 31 || hour > 23 || minute > 59 || second > 61 || yearDay > 366 {
+		return time.Time{}, invalid
+	}
+	if yearDay > 0 {
+		return time.Date(year, time.January, yearDay, hour, minute, second, nanosecond, location), nil
+	}
+	parsed := time.Date(year, time.Month(month), day, hour, minute, second, nanosecond, location)
+	if parsed.Day() != day {
+		return time.Time{}, fmt.Errorf("%s does not match format %s: day is out of range for month", value, format)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,70 @@
+package lib
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/nikolalohinski/gonja/v2/exec"
+)
+
+// versionOperators maps the comparison operators of version_compare, including the named ones of Ansible, to
+// the expected results of version.Compare.
+var versionOperators = map[string][]int{
+	"==": {0},
+	"eq": {0},
+	"!=": {-1, 1},
+	"ne": {-1, 1},
+	"<":  {-1},
+	"lt": {-1},
+	"<=": {-1, 0},
+	"le": {-1, 0},
+	">":  {1},
+	"gt": {1},
+	">=": {0, 1},
+	"ge": {0, 1},
+}
+
+// parseVersion parses a version held by a string, or by a number such as `1.25` when loaded from YAML.
+func parseVersion(value *exec.Value) (*version.Version, error) {
+	if !value.IsString() && !value.IsNumber() {
+		return nil, fmt.Errorf("%s is neither a string nor a number", value.String())
+	}
+	parsed, err := version.NewVersion(value.String())
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a valid version", value.String())
+	}
+	return parsed, nil
+}
+
+func compareVersions(left, right *version.Version, operator string) (bool, error) {
+	expected, ok := versionOperators[operator]
+	if !ok {
+		operators := make([]string, 0, len(versionOperators))
+		for name := range versionOperators {
+			operators = append(operators, name)
+		}
+		sort.Strings(operators)
+		return false, fmt.Errorf("unsupported operator %s, expected one of %s", operator, strings.Join(operators, ", "))
+	}
+	comparison := left.Compare(right)
+	for _, result := range expected {
+		if comparison == result {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// versionParts splits a version into its numeric segments, pre-release and build metadata.
+func versionParts(parsed *version.Version) map[string]interface{} {
+	segments := parsed.Segments()
+	return map[string]interface{}{
+		"major":      segments[0],
+		"minor":      segments[1],
+		"patch":      segments[2],
+		"prerelease": parsed.Prerelease(),
+		"metadata":   parsed.Metadata(),
+	}
+}
@@ -0,0 +1,258 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	defaultXMLAttributePrefix = "@"
+	defaultXMLTextKey         = "#text"
+)
+
+// xmlConvention describes how XML documents are mapped to dictionaries and lists:
+//   - an element becomes a key of its parent dictionary, and repeated elements become a list ;
+//   - attributes are keys of the element's dictionary prefixed with AttributePrefix ;
+//   - an element with only text becomes a string, and an empty element becomes an empty string ;
+//   - the text of an element with attributes or children is stored under TextKey.
+type xmlConvention struct {
+	AttributePrefix string
+	TextKey         string
+}
+
+type xmlFrame struct {
+	name     string
+	value    map[string]interface{}
+	repeated map[string]bool
+	text     strings.Builder
+	children bool
+}
+
+// decodeXML parses an XML document into a dictionary holding its root element following the given convention.
+func decodeXML(data []byte, convention xmlConvention) (map[string]interface{}, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = true
+
+	root := &xmlFrame{value: make(map[string]interface{}), repeated: make(map[string]bool)}
+	stack := []*xmlFrame{root}
+	for {
+		token, err := decoder.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch typed := token.(type) {
+		case xml.StartElement:
+			if len(stack) == 1 && len(root.value) > 0 {
+				return nil, fmt.Errorf("line %d: document has more than one root element", lineOf(decoder, data))
+			}
+			frame := &xmlFrame{
+				name:     xmlName(typed.Name),
+				value:    make(map[string]interface{}),
+				repeated: make(map[string]bool),
+			}
+			for _, attribute := range typed.Attr {
+				frame.value[convention.AttributePrefix+xmlName(attribute.Name)] = attribute.Value
+			}
+			stack[len(stack)-1].children = true
+			stack = append(stack, frame)
+		case xml.EndElement:
+			frame := stack[len(stack)-1]
+			if len(stack) == 1 || frame.name != xmlName(typed.Name) {
+				return nil, fmt.Errorf("line %d: unexpected closing element </%s>", lineOf(decoder, data), xmlName(typed.Name))
+			}
+			stack = stack[:len(stack)-1]
+			parent := stack[len(stack)-1]
+
+			var value interface{}
+			text := strings.TrimSpace(frame.text.String())
+			switch {
+			case len(frame.value) == 0 && !frame.children:
+				value = text
+			default:
+				if text != "" {
+					frame.value[convention.TextKey] = text
+				}
+				value = frame.value
+			}
+
+			existing, exists := parent.value[frame.name]
+			switch {
+			case !exists:
+				parent.value[frame.name] = value
+			case parent.repeated[frame.name]:
+				parent.value[frame.name] = append(existing.([]interface{}), value)
+			default:
+				parent.value[frame.name] = []interface{}{existing, value}
+				parent.repeated[frame.name] = true
+			}
+		case xml.CharData:
+			if len(stack) > 1 {
+				stack[len(stack)-1].text.Write(typed)
+			} else if strings.TrimSpace(string(typed)) != "" {
+				return nil, fmt.Errorf("line %d: unexpected text outside of the root element", lineOf(decoder, data))
+			}
+		}
+	}
+	if len(stack) > 1 {
+		return nil, fmt.Errorf("element <%s> is not closed", stack[len(stack)-1].name)
+	}
+	if len(root.value) == 0 {
+		return nil, errors.New("document has no root element")
+	}
+	return root.value, nil
+}
+
+func xmlName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+func lineOf(decoder *xml.Decoder, data []byte) int {
+	offset := decoder.InputOffset()
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
+
+// xmlEncoder serializes dictionaries and lists back into XML following the same convention as decodeXML.
+type xmlEncoder struct {
+	xmlConvention
+	Indent      int
+	Declaration bool
+}
+
+func (e xmlEncoder) encode(root string, value interface{}) (string, error) {
+	builder := strings.Builder{}
+	if e.Declaration {
+		builder.WriteString(xml.Header)
+	}
+	if err := e.writeElement(&builder, root, value, 0); err != nil {
+		return "", err
+	}
+	if e.Indent > 0 {
+		builder.WriteString("\n")
+	}
+	return builder.String(), nil
+}
+
+func (e xmlEncoder) writeElement(builder *strings.Builder, name string, value interface{}, depth int) error {
+	if err := validateXMLName(name); err != nil {
+		return err
+	}
+	padding := strings.Repeat(" ", e.Indent*depth)
+	switch typed := value.(type) {
+	case []interface{}:
+		if depth == 0 {
+			return fmt.Errorf("root element <%s> can not be a list", name)
+		}
+		for index, item := range typed {
+			if _, isList := item.([]interface{}); isList {
+				return fmt.Errorf("element <%s> holds a list of lists which can not be represented in XML", name)
+			}
+			if index > 0 && e.Indent > 0 {
+				builder.WriteString("\n")
+			}
+			if err := e.writeElement(builder, name, item, depth); err != nil {
+				return err
+			}
+		}
+		return nil
+	case map[string]interface{}:
+		builder.WriteString(padding + "<" + name)
+		children := []string{}
+		var text interface{}
+		for _, key := range sortedKeys(typed) {
+			switch {
+			case key == e.TextKey:
+				text = typed[key]
+			case e.AttributePrefix != "" && strings.HasPrefix(key, e.AttributePrefix):
+				attribute := strings.TrimPrefix(key, e.AttributePrefix)
+				if err := validateXMLName(attribute); err != nil {
+					return err
+				}
+				serialized, err := scalarToString(typed[key])
+				if err != nil {
+					return fmt.Errorf("attribute %s of element <%s>: %s", attribute, name, err)
+				}
+				builder.WriteString(" " + attribute + "=\"" + escapeXML(serialized) + "\"")
+			default:
+				children = append(children, key)
+			}
+		}
+		serializedText, err := scalarToString(text)
+		if err != nil {
+			return fmt.Errorf("text of element <%s>: %s", name, err)
+		}
+		if len(children) == 0 && serializedText == "" {
+			builder.WriteString("/>")
+			return nil
+		}
+		builder.WriteString(">" + escapeXML(serializedText))
+		if len(children) == 0 {
+			builder.WriteString("</" + name + ">")
+			return nil
+		}
+		for _, child := range children {
+			if e.Indent > 0 {
+				builder.WriteString("\n")
+			}
+			if err := e.writeElement(builder, child, typed[child], depth+1); err != nil {
+				return err
+			}
+		}
+		if e.Indent > 0 {
+			builder.WriteString("\n" + padding)
+		}
+		builder.WriteString("</" + name + ">")
+		return nil
+	default:
+		serialized, err := scalarToString(value)
+		if err != nil {
+			return fmt.Errorf("element <%s>: %s", name, err)
+		}
+		if serialized == "" {
+			builder.WriteString(padding + "<" + name + "/>")
+			return nil
+		}
+		builder.WriteString(padding + "<" + name + ">" + escapeXML(serialized) + "</" + name + ">")
+		return nil
+	}
+}
+
+var xmlEscaper = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+	"\"", "&quot;",
+	"\r", "&#xD;",
+)
+
+func escapeXML(value string) string {
+	return xmlEscaper.Replace(value)
+}
+
+func validateXMLName(name string) error {
+	if name == "" {
+		return errors.New("element and attribute names can not be empty")
+	}
+	for index, character := range name {
+		valid := character == '_' || character == ':' || (character >= 'a' && character <= 'z') || (character >= 'A' && character <= 'Z') || character > 0x7f
+		if index > 0 {
+			valid = valid || character == '-' || character == '.' || (character >= '0' && character <= '9')
+		}
+		if !valid {
+			return fmt.Errorf("%q is not a valid XML name", name)
+		}
+	}
+	return nil
+}
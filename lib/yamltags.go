@@ -0,0 +1,201 @@
+package lib
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	yamlTagInclude = "!include"
+	yamlTagFile    = "!file"
+	yamlTagEnv     = "!env"
+	yamlTagBase64  = "!base64"
+)
+
+// yamlTagResolver replaces the custom tags of YAML documents by the values they point to. Relative paths are
+// resolved against the source directory, the files currently being included are kept in a stack to detect
+// include cycles, and every file read is recorded in inputs.
+type yamlTagResolver struct {
+	directory string
+	strict    bool
+	including []string
+	inputs    map[string]string
+}
+
+// decodeTaggedYAML decodes a YAML document after resolving the `!include`, `!file`, `!env` and `!base64` tags
+// it holds. The given file, if any, is the one the document was read from. Included documents are checked like
+// the root one when parsing strictly. The files read through tags are added to the given inputs like ExpandFiles
+// records them.
+func decodeTaggedYAML(data []byte, directory, file string, strict bool, inputs map[string]string) (interface{}, error) {
+	resolver := &yamlTagResolver{directory: directory, strict: strict, inputs: inputs}
+	if file != "" {
+		resolver.including = append(resolver.including, resolver.resolvePath(file))
+	}
+	document := new(yaml.Node)
+	if err := yaml.Unmarshal(data, document); err != nil {
+		return nil, err
+	}
+	if strict {
+		if err := checkStrictNode(document); err != nil {
+			return nil, err
+		}
+	}
+	if err := resolver.resolve(document); err != nil {
+		return nil, err
+	}
+	if document.Kind == 0 {
+		return make(map[string]interface{}), nil
+	}
+	layer := make(map[string]interface{})
+	if err := document.Decode(&layer); err == nil {
+		return layer, nil
+	}
+	var decoded interface{}
+	if err := document.Decode(&decoded); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
+
+func (r *yamlTagResolver) resolvePath(path string) string {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(r.directory, path)
+	}
+	if absolute, err := filepath.Abs(path); err == nil {
+		return absolute
+	}
+	return filepath.Clean(path)
+}
+
+func (r *yamlTagResolver) resolve(node *yaml.Node) error {
+	switch node.Tag {
+	case yamlTagInclude:
+		return r.include(node)
+	case yamlTagFile:
+		path, err := r.scalar(node)
+		if err != nil {
+			return err
+		}
+		resolved := r.resolvePath(path)
+		content, err := os.ReadFile(resolved)
+		if err != nil {
+			return fmt.Errorf("line %d: failed to read file %s: %s", node.Line, path, err)
+		}
+		recordInput(r.inputs, r.directory, resolved, content)
+		setStringNode(node, string(content))
+		return nil
+	case yamlTagEnv:
+		return r.env(node)
+	case yamlTagBase64:
+		encoded, err := r.scalar(node)
+		if err != nil {
+			return err
+		}
+		decoded, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(encoded), ""))
+		if err != nil {
+			return fmt.Errorf("line %d: failed to decode base64 value: %s", node.Line, err)
+		}
+		setStringNode(node, string(decoded))
+		return nil
+	}
+	for _, child := range node.Content {
+		if err := r.resolve(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *yamlTagResolver) include(node *yaml.Node) error {
+	path, err := r.scalar(node)
+	if err != nil {
+		return err
+	}
+	resolved := r.resolvePath(path)
+	for index, included := range r.including {
+		if included == resolved {
+			cycle := append(append([]string{}, r.including[index:]...), resolved)
+			for position := range cycle {
+				if relative, err := filepath.Rel(r.directory, cycle[position]); err == nil {
+					cycle[position] = filepath.ToSlash(relative)
+				}
+			}
+			return fmt.Errorf("include cycle detected: %s", strings.Join(cycle, " -> "))
+		}
+	}
+	data, err := os.ReadFile(resolved)
+	if err != nil {
+		return fmt.Errorf("line %d: failed to read included file %s: %s", node.Line, path, err)
+	}
+	recordInput(r.inputs, r.directory, resolved, data)
+	document := new(yaml.Node)
+	if err := yaml.Unmarshal(data, document); err != nil {
+		return fmt.Errorf("failed to unmarshal included file %s: %s", path, err)
+	}
+	if r.strict {
+		if err := checkStrictNode(document); err != nil {
+			return fmt.Errorf("%s: %s", path, err)
+		}
+	}
+	r.including = append(r.including, resolved)
+	defer func() { r.including = r.including[:len(r.including)-1] }()
+	if err := r.resolve(document); err != nil {
+		return fmt.Errorf("%s: %s", path, err)
+	}
+	if document.Kind == 0 || len(document.Content) == 0 {
+		*node = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null", Line: node.Line, Column: node.Column}
+		return nil
+	}
+	*node = *document.Content[0]
+	return nil
+}
+
+// env resolves `!env NAME` and `!env [NAME, default]` nodes with the same rules as the env global: a variable
+// that is not set fails the decoding unless a non empty default is given.
+func (r *yamlTagResolver) env(node *yaml.Node) error {
+	var name, defaultValue string
+	switch node.Kind {
+	case yaml.ScalarNode:
+		name = node.Value
+	case yaml.SequenceNode:
+		if len(node.Content) == 0 || len(node.Content) > 2 {
+			return fmt.Errorf("line %d: %s expects a variable name and an optional default value", node.Line, yamlTagEnv)
+		}
+		for _, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				return fmt.Errorf("line %d: %s expects a variable name and an optional default value", node.Line, yamlTagEnv)
+			}
+		}
+		name = node.Content[0].Value
+		if len(node.Content) == 2 {
+			defaultValue = node.Content[1].Value
+		}
+	default:
+		return fmt.Errorf("line %d: %s expects a variable name and an optional default value", node.Line, yamlTagEnv)
+	}
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		if defaultValue == "" {
+			return fmt.Errorf("line %d: failed to get '%s' environment variable without default", node.Line, name)
+		}
+		value = defaultValue
+	}
+	setStringNode(node, value)
+	return nil
+}
+
+func (r *yamlTagResolver) scalar(node *yaml.Node) (string, error) {
+	if node.Kind != yaml.ScalarNode {
+		return "", fmt.Errorf("line %d: %s expects a string", node.Line, node.Tag)
+	}
+	return node.Value, nil
+}
+
+func setStringNode(node *yaml.Node, value string) {
+	*node = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value, Line: node.Line, Column: node.Column}
+}